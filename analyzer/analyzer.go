@@ -0,0 +1,251 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"regexp"
+	"strings"
+
+	doc "github.com/uccu/go-doc"
+	"golang.org/x/tools/go/analysis"
+)
+
+// 检查接口注解, 可用于 go vet -vettool 与 multichecker
+var Analyzer = &analysis.Analyzer{
+	Name: "godoc",
+	Doc:  "check go-doc API annotations",
+	Run:  run,
+}
+
+var casing string
+var extra string
+
+func init() {
+	Analyzer.Flags.StringVar(&casing, "casing", "", "json name convention of request and response fields: camel, pascal, snake or kebab")
+	Analyzer.Flags.StringVar(&extra, "annotations", "", "comma separated names of custom annotations registered with RegisterAnnotation")
+}
+
+var casings = map[string]*regexp.Regexp{
+	"camel":  regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`),
+	"pascal": regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`),
+	"snake":  regexp.MustCompile(`^[a-z][a-z0-9_]*$`),
+	"kebab":  regexp.MustCompile(`^[a-z][a-z0-9-]*$`),
+}
+
+var methods = map[string]bool{
+	"get": true, "post": true, "put": true, "patch": true, "delete": true,
+	"head": true, "options": true, "connect": true, "trace": true,
+}
+
+type annotation struct {
+	name string
+	args []string
+	pos  token.Pos
+}
+
+type checker struct {
+	pass    *analysis.Pass
+	file    *ast.File
+	known   map[string]bool
+	checked map[*types.Var]bool
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	c := &checker{
+		pass:    pass,
+		known:   make(map[string]bool),
+		checked: make(map[*types.Var]bool),
+	}
+	for _, name := range doc.Annotations() {
+		c.known[name] = true
+	}
+	for _, name := range strings.Split(extra, ",") {
+		if name = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(name), "@")); name != "" {
+			c.known[name] = true
+		}
+	}
+
+	for _, f := range pass.Files {
+		c.file = f
+		for _, d := range f.Decls {
+			fn, ok := d.(*ast.FuncDecl)
+			if !ok || fn.Doc == nil {
+				continue
+			}
+			c.checkFunc(fn)
+		}
+	}
+	return nil, nil
+}
+
+func (c *checker) checkFunc(fn *ast.FuncDecl) {
+	list := []*annotation{}
+	found := make(map[string]*annotation)
+	for _, v := range doc.ParseAnnotations(fn.Doc) {
+		a := &annotation{name: v.Name, args: v.Args, pos: v.Pos}
+		list = append(list, a)
+		if _, ok := found[v.Name]; !ok {
+			found[v.Name] = a
+		}
+	}
+	if len(list) == 0 {
+		return
+	}
+
+	for _, a := range list {
+		if !c.known[a.name] {
+			c.pass.Reportf(a.pos, "unknown annotation @%s", a.name)
+		}
+	}
+
+	router, ok := found["Router"]
+	if !ok {
+		return
+	}
+	if _, ok := found["Summary"]; !ok && doc.Synopsis(fn.Doc) == "" {
+		c.pass.Reportf(fn.Doc.Pos(), "missing @Summary for %s", fn.Name.Name)
+	}
+
+	if m, ok := found["Method"]; ok && !isWs(found["Type"]) {
+		unknown := []string{}
+		for _, arg := range m.args {
+			for _, v := range strings.Split(arg, ",") {
+				if v != "" && !methods[strings.ToLower(v)] {
+					unknown = append(unknown, v)
+				}
+			}
+		}
+		if len(unknown) > 0 {
+			c.pass.Reportf(m.pos, "unknown HTTP method %s", strings.Join(unknown, ", "))
+		}
+	}
+
+	if len(router.args) > 0 {
+		c.checkPathParams(router, found["Rest"], list)
+	}
+
+	if casing != "" {
+		for _, a := range list {
+			switch a.name {
+			case "Rest", "Body":
+				if len(a.args) > 0 {
+					c.checkCasing(a, c.lookupType(a.args[0], a.pos), make(map[types.Type]bool))
+				}
+			case "Success", "Fail":
+				if len(a.args) > 2 {
+					c.checkCasing(a, c.lookupType(a.args[2], a.pos), make(map[types.Type]bool))
+				}
+			}
+		}
+	}
+}
+
+func isWs(a *annotation) bool {
+	return a != nil && len(a.args) > 0 && a.args[0] == "ws"
+}
+
+func (c *checker) checkPathParams(router, rest *annotation, list []*annotation) {
+	params := doc.PathParams(router.args[0])
+	if len(params) == 0 {
+		return
+	}
+
+	names := make(map[string]bool)
+	if rest != nil && len(rest.args) > 0 {
+		if s := structOf(c.lookupType(rest.args[0], rest.pos)); s != nil {
+			fieldNames(s, names)
+		}
+	}
+	for _, a := range list {
+		if a.name == "Param" && len(a.args) > 1 && a.args[1] == "path" {
+			names[strings.ToLower(a.args[0])] = true
+		}
+	}
+	for _, p := range params {
+		if !names[strings.ToLower(p)] {
+			c.pass.Reportf(router.pos, "path parameter %s has no matching @Rest field or @Param", p)
+		}
+	}
+}
+
+func fieldNames(s *types.Struct, names map[string]bool) {
+	for i := 0; i < s.NumFields(); i++ {
+		f := s.Field(i)
+		if f.Embedded() {
+			if e := structOf(f.Type()); e != nil {
+				fieldNames(e, names)
+			}
+			continue
+		}
+		names[strings.ToLower(f.Name())] = true
+		tag := reflect.StructTag(s.Tag(i))
+		for _, key := range []string{"uri", "json"} {
+			if name := tagName(tag, key); name != "" {
+				names[strings.ToLower(name)] = true
+			}
+		}
+	}
+}
+
+func (c *checker) checkCasing(a *annotation, t types.Type, seen map[types.Type]bool) {
+	if t == nil || seen[t] {
+		return
+	}
+	seen[t] = true
+
+	switch u := t.Underlying().(type) {
+	case *types.Pointer:
+		c.checkCasing(a, u.Elem(), seen)
+	case *types.Slice:
+		c.checkCasing(a, u.Elem(), seen)
+	case *types.Array:
+		c.checkCasing(a, u.Elem(), seen)
+	case *types.Map:
+		c.checkCasing(a, u.Elem(), seen)
+	case *types.Struct:
+		for i := 0; i < u.NumFields(); i++ {
+			f := u.Field(i)
+			c.checkCasing(a, f.Type(), seen)
+			name := tagName(reflect.StructTag(u.Tag(i)), "json")
+			if name == "" || f.Embedded() || casings[casing] == nil || casings[casing].MatchString(name) || c.checked[f] {
+				continue
+			}
+			c.checked[f] = true
+			pos := a.pos
+			if f.Pkg() == c.pass.Pkg {
+				pos = f.Pos()
+			}
+			c.pass.Reportf(pos, "json name %s of field %s is not %s case", name, f.Name(), casing)
+		}
+	}
+}
+
+// 按注解中的类型表达式查找类型, 如 Name, *pkg.Name, []Name 与 map[string]Name
+func (c *checker) lookupType(name string, pos token.Pos) types.Type {
+	tv, err := types.Eval(c.pass.Fset, c.pass.Pkg, pos, name)
+	if err != nil || !tv.IsType() {
+		return nil
+	}
+	return tv.Type
+}
+
+func structOf(t types.Type) *types.Struct {
+	if t == nil {
+		return nil
+	}
+	if p, ok := t.Underlying().(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	s, _ := t.Underlying().(*types.Struct)
+	return s
+}
+
+func tagName(tag reflect.StructTag, key string) string {
+	name := strings.Split(tag.Get(key), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,15 @@
+package analyzer
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	Analyzer.Flags.Set("casing", "camel")
+	defer Analyzer.Flags.Set("casing", "")
+	Analyzer.Flags.Set("annotations", "Owner")
+	defer Analyzer.Flags.Set("annotations", "")
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "a")
+}
@@ -0,0 +1,14 @@
+// go-doc-vet 检查接口注解
+//
+//	go install github.com/uccu/go-doc/analyzer/cmd/go-doc-vet@latest
+//	go vet -vettool=$(which go-doc-vet) ./...
+package main
+
+import (
+	"github.com/uccu/go-doc/analyzer"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(analyzer.Analyzer)
+}
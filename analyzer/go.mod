@@ -0,0 +1,16 @@
+module github.com/uccu/go-doc/analyzer
+
+go 1.22.0
+
+require (
+	github.com/uccu/go-doc v0.1.0
+	golang.org/x/tools v0.30.0
+)
+
+require (
+	github.com/fatih/structtag v1.2.0 // indirect
+	github.com/uccu/go-stringify v0.4.3 // indirect
+	golang.org/x/mod v0.23.0 // indirect
+	golang.org/x/sync v0.11.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,54 @@
+package a
+
+import m "a/model"
+
+type GetReq struct {
+	Id int64 `uri:"id"`
+}
+
+type Resp struct {
+	NickName string  `json:"nick_name"` // want `json name nick_name of field NickName is not camel case`
+	User     *m.User `json:"user"`
+}
+
+// @Summary 用户信息
+// @Router /user/:id
+// @Owner team-user
+// @Rest GetReq
+// @Success 200 data Resp // want `json name user_name of field UserName is not camel case`
+func Get() {}
+
+// @Summary 更新用户
+// @Router /user/{id}/{field}		// want `path parameter field has no matching @Rest field or @Param`
+// @Method post,fetch				// want `unknown HTTP method fetch`
+// @Rest GetReq
+// @Succes 200 data Resp			// want `unknown annotation @Succes`
+func Update() {}
+
+// @Router /user/list // want `missing @Summary for List`
+func List() {}
+
+// Detail 用户详情, 摘要取自函数注释.
+// @Router /user/:id/detail
+// @Rest GetReq
+func Detail() {}
+
+// @Summary 用户订单
+// @Router /user/:uid/order/:oid
+// @Param uid path int64 true 用户
+// @Param oid path int64 true "订单 id"
+// @Param page query int false
+func Orders() {}
+
+type Item struct {
+	ItemName string `json:"item_name"` // want `json name item_name of field ItemName is not camel case`
+}
+
+// @Summary 商品列表
+// @Router /items
+// @Success 200 list []Item
+// @Success 200 index map[string]*Item
+func Items() {}
+
+// 普通函数
+func Plain() {}
@@ -0,0 +1,6 @@
+package model
+
+type User struct {
+	Id       int64  `json:"id"`
+	UserName string `json:"user_name"`
+}
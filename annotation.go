@@ -0,0 +1,105 @@
+package doc
+
+import (
+	"fmt"
+	"go/token"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// 注解的解析函数, args 为拆分后的参数, 返回 false 表示参数不正确
+type AnnotationHandler func(api *DocApi, ctx *AnnotationContext, args []string) bool
+
+// 注解所在的包与文件
+type AnnotationContext struct {
+	Name string // 注解名
+	Pkg  *Pkg
+	File string
+	Pos  token.Position
+	api  *DocApi
+}
+
+func (ctx *AnnotationContext) Errorf(format string, a ...interface{}) {
+	ctx.api.report(SeverityError, format, a...)
+}
+
+func (ctx *AnnotationContext) Warnf(format string, a ...interface{}) {
+	ctx.api.report(SeverityWarning, format, a...)
+}
+
+var handlersLock sync.RWMutex
+var handlers map[string]AnnotationHandler
+
+// 在 init 中赋值, 避免与解析时计算缓存 key 用到的 Annotations 形成初始化循环
+func init() {
+	handlers = map[string]AnnotationHandler{
+		"Summary":      method((*DocApi).ParseSummary),
+		"Desc":         method((*DocApi).ParseDescription),
+		"Description":  method((*DocApi).ParseDescription),
+		"Category":     method((*DocApi).ParseCategory),
+		"Router":       method((*DocApi).ParseRouter),
+		"Type":         method((*DocApi).ParseType),
+		"Server":       method((*DocApi).ParseServer),
+		"Method":       method((*DocApi).ParseMethod),
+		"Tag":          method((*DocApi).ParseTag),
+		"Tags":         method((*DocApi).ParseTag),
+		"Accept":       method((*DocApi).ParseAccept),
+		"Header":       method((*DocApi).ParseHeader),
+		"Param":        method((*DocApi).ParseParam),
+		"Rest":         method((*DocApi).ParseRest),
+		"Body":         method((*DocApi).ParseBody),
+		"Success":      method((*DocApi).ParseSuccess),
+		"Fail":         method((*DocApi).ParseFail),
+		"Order":        method((*DocApi).ParseOrder),
+		"NoEnvelope":   method((*DocApi).ParseNoEnvelope),
+		"FailRef":      method((*DocApi).ParseFailRef),
+		"HeaderRef":    method((*DocApi).ParseHeaderRef),
+		"Prefix":       method((*DocApi).ParsePrefix),
+		"Use":          method((*DocApi).ParseUse),
+		"Middleware":   method((*DocApi).ParseMiddleware),
+		"DefineFail":   method((*DocApi).parseDefine),
+		"DefineHeader": method((*DocApi).parseDefine),
+	}
+}
+
+var annotationName = regexp.MustCompile(`^[a-zA-Z]+$`)
+
+func method(f func(*DocApi, []string) bool) AnnotationHandler {
+	return func(api *DocApi, _ *AnnotationContext, args []string) bool {
+		return f(api, args)
+	}
+}
+
+// 注册自定义注解, 同名时替换原有的处理函数.
+// 应在解析前调用, 一般放在 init 中
+func RegisterAnnotation(name string, handler AnnotationHandler) {
+	if !annotationName.MatchString(name) {
+		panic(fmt.Sprintf("go-doc: invalid annotation name %q", name))
+	}
+	if handler == nil {
+		panic("go-doc: nil handler for annotation @" + name)
+	}
+	handlersLock.Lock()
+	defer handlersLock.Unlock()
+	handlers[name] = handler
+}
+
+func annotationHandler(name string) (AnnotationHandler, bool) {
+	handlersLock.RLock()
+	defer handlersLock.RUnlock()
+	h, ok := handlers[name]
+	return h, ok
+}
+
+// 支持的注解名, 包含注册的自定义注解
+func Annotations() []string {
+	handlersLock.RLock()
+	defer handlersLock.RUnlock()
+	list := make([]string, 0, len(handlers))
+	for name := range handlers {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
@@ -0,0 +1,64 @@
+package doc
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRegisterAnnotation(t *testing.T) {
+	RegisterAnnotation("Owner", func(api *DocApi, ctx *AnnotationContext, args []string) bool {
+		if len(args) == 0 {
+			return false
+		}
+		api.SetExtension("owner", strings.Join(args, " "))
+		return true
+	})
+	RegisterAnnotation("RateLimit", func(api *DocApi, ctx *AnnotationContext, args []string) bool {
+		if len(args) == 0 {
+			return false
+		}
+		n, err := strconv.Atoi(args[0])
+		if err != nil {
+			ctx.Errorf("rate limit %s is not a number", args[0])
+			return false
+		}
+		api.SetExtension("x-rate-limit", n)
+		return true
+	})
+	RegisterAnnotation("FeatureFlag", func(api *DocApi, ctx *AnnotationContext, args []string) bool {
+		if !strings.HasSuffix(ctx.File, "api.go") || ctx.Pkg == nil || ctx.Pos.Line != 7 {
+			t.Errorf("context = %+v", ctx)
+		}
+		api.SetExtension("featureFlag", args)
+		return len(args) > 0
+	})
+
+	ssdoc := NewSSDoc(SSDocInfo{}, nil).AddPacakges("github.com/uccu/go-doc/testdata/extension")
+	apis := ssdoc.Apis["default"]
+	if len(apis) != 2 {
+		t.Fatalf("apis = %d", len(apis))
+	}
+	if e := apis[0].Extensions; e["owner"] != "交易 团队" || e["x-rate-limit"] != 100 {
+		t.Errorf("extensions = %v", e)
+	}
+	if d := ssdoc.Diagnostics(); len(d) != 1 || d[0].Annotation != "RateLimit" || d[0].Pos.Line != 12 {
+		t.Errorf("diagnostics = %v", d)
+	}
+
+	js, _ := json.Marshal(ssdoc.OpenAPI().Paths["/order"]["post"])
+	for _, s := range []string{`"x-owner":"交易 团队"`, `"x-rate-limit":100`, `"x-featureFlag":["new-order"]`} {
+		if !strings.Contains(string(js), s) {
+			t.Errorf("openapi missing %s: %s", s, js)
+		}
+	}
+
+	found := false
+	for _, name := range Annotations() {
+		found = found || name == "RateLimit"
+	}
+	if !found {
+		t.Error("registered annotation not listed")
+	}
+}
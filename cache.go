@@ -0,0 +1,395 @@
+package doc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/fatih/structtag"
+)
+
+var cacheDir string
+
+// 解析结果的格式或含义变化时递增, 使旧缓存失效
+const cacheFormat = 13
+
+// 设置后, 包的解析结果按文件内容缓存到该目录, 文件未变化时不再重新解析
+func SetCacheDir(dir string) {
+	cacheDir = dir
+}
+
+type pkgCache struct {
+	Name    string                       `json:"name"`
+	Imports map[string]map[string]string `json:"imports"`
+	Stru    map[string]*cacheType        `json:"stru"`
+	Apis    []*cacheApi                  `json:"apis"`
+	Defines *cacheDefines                `json:"defines,omitempty"`
+}
+
+type cacheDefines struct {
+	Fail   map[string][]*cacheRet  `json:"fail,omitempty"`
+	Header map[string][]*DocHeader `json:"header,omitempty"`
+	Diags  []*Diagnostic           `json:"diags,omitempty"`
+}
+
+type cacheType struct {
+	Key      string        `json:"key,omitempty"`
+	Tags     string        `json:"tags,omitempty"`
+	Pkg      string        `json:"pkg,omitempty"`
+	Ref      string        `json:"ref,omitempty"` // 引用包内定义的类型
+	File     string        `json:"file,omitempty"`
+	Name     string        `json:"name,omitempty"`
+	TypeName string        `json:"typeName,omitempty"`
+	Kind     reflect.Kind  `json:"kind"`
+	Type     Type          `json:"type"`
+	Value    []*cacheType  `json:"value,omitempty"`
+	Doc      []string      `json:"doc,omitempty"`
+	Comment  string        `json:"comment,omitempty"`
+	Diags    []*Diagnostic `json:"diags,omitempty"`
+}
+
+type cacheApi struct {
+	DocApi
+	File    string                    `json:"file"`
+	Param   []*cacheParam             `json:"param,omitempty"`
+	Rest    *cacheType                `json:"rest,omitempty"`
+	Body    *cacheType                `json:"body,omitempty"`
+	Success []*cacheRet               `json:"success,omitempty"`
+	Fail    []*cacheRet               `json:"fail,omitempty"`
+	Diags   []*Diagnostic             `json:"diags,omitempty"`
+	Pos     map[string]token.Position `json:"pos,omitempty"`
+}
+
+type cacheParam struct {
+	Name     string     `json:"name"`
+	In       string     `json:"in"`
+	Value    *cacheType `json:"value"`
+	Required bool       `json:"required,omitempty"`
+	Remark   string     `json:"remark,omitempty"`
+}
+
+type cacheRet struct {
+	Code        int16      `json:"code"`
+	Key         string     `json:"key"`
+	Value       *cacheType `json:"value"`
+	Description string     `json:"description,omitempty"`
+}
+
+// 由go-doc版本, 编译条件, 注册的注解与目录下参与编译的文件内容计算.
+// 前缀只由包与编译条件计算, 同一前缀的旧缓存在写入新缓存时删除
+func (l *loader) cacheKey(pkgName, dir string) string {
+	if cacheDir == "" {
+		return ""
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+
+	id := sha256.Sum256([]byte(fmt.Sprintln(pkgName, dir, l.ctx.GOOS, l.ctx.GOARCH, l.ctx.BuildTags)))
+	h := sha256.New()
+	fmt.Fprintln(h, version, cacheFormat, pkgName, dir, l.ctx.GOOS, l.ctx.GOARCH, l.ctx.BuildTags)
+	fmt.Fprintln(h, Annotations())
+	match := l.matchFile(dir)
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".go") || !match(f) {
+			continue
+		}
+		b, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return ""
+		}
+		fmt.Fprintln(h, f.Name(), len(b))
+		h.Write(b)
+	}
+	return hex.EncodeToString(id[:8]) + "-" + hex.EncodeToString(h.Sum(nil))
+}
+
+// key 只由包内的文件计算, 引用的其它包中的类型改名或删除时缓存失效, 重新解析以报告无法解析的类型
+func (l *loader) validCache(pkgName string, c *pkgCache) bool {
+	valid := true
+	var check func(t *cacheType)
+	check = func(t *cacheType) {
+		if t == nil || !valid {
+			return
+		}
+		if t.Ref != "" && t.Pkg != "" && t.Pkg != pkgName {
+			p := l.get(t.Pkg)
+			valid = p != nil && p.GetStru(t.Ref) != nil
+			return
+		}
+		for _, v := range t.Value {
+			check(v)
+		}
+	}
+	checkRets := func(list []*cacheRet) {
+		for _, r := range list {
+			check(r.Value)
+		}
+	}
+
+	for _, a := range c.Apis {
+		check(a.Rest)
+		check(a.Body)
+		for _, p := range a.Param {
+			check(p.Value)
+		}
+		checkRets(a.Success)
+		checkRets(a.Fail)
+	}
+	if c.Defines != nil {
+		for _, list := range c.Defines.Fail {
+			checkRets(list)
+		}
+	}
+	return valid
+}
+
+func readCache(key string) *pkgCache {
+	if key == "" {
+		return nil
+	}
+	b, err := ioutil.ReadFile(filepath.Join(cacheDir, key+".json"))
+	if err != nil {
+		return nil
+	}
+	c := &pkgCache{}
+	if json.Unmarshal(b, c) != nil {
+		return nil
+	}
+	return c
+}
+
+// 写入所有新解析的包, 包括只提供类型的包. 写入时可能加载新的包, 直到全部写入
+func (l *loader) saveCache() {
+	for {
+		l.lock.Lock()
+		list := l.parsed
+		l.parsed = nil
+		l.lock.Unlock()
+		if len(list) == 0 {
+			return
+		}
+		parallel(len(list), func(i int) {
+			list[i].SetPkgs().SetStru()
+			list[i].saveCache(list[i].GetApis())
+		})
+	}
+}
+
+func (pkg *Pkg) saveCache(apis []*DocApi) error {
+	if pkg.key == "" || pkg.cache != nil {
+		return nil
+	}
+
+	c := &pkgCache{
+		Name:    pkg.Name,
+		Imports: make(map[string]map[string]string),
+		Stru:    make(map[string]*cacheType),
+		Apis:    make([]*cacheApi, 0, len(apis)),
+	}
+	for file, m := range pkg.SetPkgs().imports {
+		c.Imports[file] = m
+	}
+	for name, ts := range pkg.SetStru().stru {
+		if ts != nil {
+			c.Stru[name] = pkg.encodeType(&TypeSpecWithKey{TypeSpec: ts}, false)
+		}
+	}
+	for _, api := range apis {
+		c.Apis = append(c.Apis, pkg.encodeApi(api))
+	}
+	c.Defines = pkg.encodeDefines(pkg.Defines())
+
+	b, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(cacheDir, os.ModePerm); err != nil {
+		return err
+	}
+	file := filepath.Join(cacheDir, pkg.key+".json")
+	if err := ioutil.WriteFile(file+".tmp", b, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(file+".tmp", file); err != nil {
+		return err
+	}
+
+	// 删除同一个包在文件变化前的缓存
+	old, _ := filepath.Glob(filepath.Join(cacheDir, pkg.key[:strings.Index(pkg.key, "-")+1]+"*.json"))
+	for _, f := range old {
+		if f != file {
+			os.Remove(f)
+		}
+	}
+	return nil
+}
+
+func (pkg *Pkg) lookup(pkgName string) *Pkg {
+	if pkgName == "" || pkgName == pkg.Path {
+		return pkg
+	}
+	return pkg.loader.get(pkgName)
+}
+
+func (pkg *Pkg) encodeType(t *TypeSpecWithKey, ref bool) *cacheType {
+	if t == nil {
+		return nil
+	}
+
+	c := &cacheType{Key: t.Key}
+	if t.Tags != nil {
+		c.Tags = t.Tags.String()
+	}
+	if t.pkg != nil && t.pkg != pkg {
+		c.Pkg = t.pkg.Path
+	}
+
+	if ref && t.pkg != nil && t.Name != "" && t.pkg.GetStru(t.Name) == t.TypeSpec {
+		c.Ref = t.Name
+		return c
+	}
+
+	c.File = t.file
+	c.Name = t.Name
+	c.TypeName = t.TypeName
+	c.Kind = t.Kind
+	c.Type = t.Type
+	c.Doc = t.Doc
+	c.Comment = t.Comment
+	c.Diags = t.diags
+	for _, v := range t.Value {
+		c.Value = append(c.Value, pkg.encodeType(v, ref))
+	}
+	return c
+}
+
+func (pkg *Pkg) decodeType(c *cacheType) *TypeSpecWithKey {
+	if c == nil {
+		return nil
+	}
+
+	t := &TypeSpecWithKey{Key: c.Key}
+	if c.Tags != "" {
+		t.Tags, _ = structtag.Parse(c.Tags)
+	}
+
+	p := pkg.lookup(c.Pkg)
+	if c.Ref != "" {
+		if p == nil {
+			return nil
+		}
+		t.TypeSpec = p.GetStru(c.Ref)
+		if t.TypeSpec == nil {
+			return nil
+		}
+		return t
+	}
+
+	t.TypeSpec = &TypeSpec{
+		Name:     c.Name,
+		TypeName: c.TypeName,
+		Kind:     c.Kind,
+		Type:     c.Type,
+		pkg:      p,
+		file:     c.File,
+		Doc:      c.Doc,
+		Comment:  c.Comment,
+		diags:    c.Diags,
+	}
+	for _, v := range c.Value {
+		if v := pkg.decodeType(v); v != nil {
+			t.Value = append(t.Value, v)
+		}
+	}
+	return t
+}
+
+func (pkg *Pkg) encodeApi(api *DocApi) *cacheApi {
+	c := &cacheApi{
+		DocApi: *api,
+		File:   api.file,
+		Rest:   pkg.encodeType(api.Rest, true),
+		Body:   pkg.encodeType(api.Body, true),
+		Diags:  api.diags,
+		Pos:    api.positions,
+	}
+	for _, p := range api.Param {
+		c.Param = append(c.Param, &cacheParam{Name: p.Name, In: p.In, Value: pkg.encodeType(p.Value, true), Required: p.Required, Remark: p.Remark})
+	}
+	for _, r := range api.Success {
+		c.Success = append(c.Success, &cacheRet{Code: r.Code, Key: r.Key, Value: pkg.encodeType(r.Value, true), Description: r.Description})
+	}
+	for _, r := range api.Fail {
+		c.Fail = append(c.Fail, &cacheRet{Code: r.Code, Key: r.Key, Value: pkg.encodeType(r.Value, true), Description: r.Description})
+	}
+	return c
+}
+
+func (pkg *Pkg) encodeDefines(d *DocDefines) *cacheDefines {
+	c := &cacheDefines{
+		Fail:   make(map[string][]*cacheRet),
+		Header: d.Header,
+		Diags:  d.diags,
+	}
+	for name, list := range d.Fail {
+		for _, r := range list {
+			c.Fail[name] = append(c.Fail[name], &cacheRet{Code: r.Code, Key: r.Key, Value: pkg.encodeType(r.Value, true), Description: r.Description})
+		}
+	}
+	return c
+}
+
+func (pkg *Pkg) decodeDefines(c *cacheDefines) {
+	if c == nil {
+		return
+	}
+	for name, list := range c.Fail {
+		for _, r := range list {
+			if v := pkg.decodeType(r.Value); v != nil {
+				pkg.defines.Fail[name] = append(pkg.defines.Fail[name], &DocRet{Code: r.Code, Key: r.Key, Value: v, Description: r.Description})
+			}
+		}
+	}
+	for name, list := range c.Header {
+		pkg.defines.Header[name] = list
+	}
+	pkg.defines.diags = c.Diags
+}
+
+func (pkg *Pkg) decodeApi(c *cacheApi) *DocApi {
+	api := c.DocApi
+	api.pkg = pkg
+	api.file = c.File
+	api.Rest = pkg.decodeType(c.Rest)
+	api.Body = pkg.decodeType(c.Body)
+	api.diags = c.Diags
+	api.positions = c.Pos
+	api.Param = nil
+	for _, p := range c.Param {
+		if v := pkg.decodeType(p.Value); v != nil {
+			api.Param = append(api.Param, &DocParam{Name: p.Name, In: p.In, Value: v, Required: p.Required, Remark: p.Remark})
+		}
+	}
+	api.Success = nil
+	api.Fail = nil
+	for _, r := range c.Success {
+		if v := pkg.decodeType(r.Value); v != nil {
+			api.Success = append(api.Success, &DocRet{Code: r.Code, Key: r.Key, Value: v, Description: r.Description})
+		}
+	}
+	for _, r := range c.Fail {
+		if v := pkg.decodeType(r.Value); v != nil {
+			api.Fail = append(api.Fail, &DocRet{Code: r.Code, Key: r.Key, Value: v, Description: r.Description})
+		}
+	}
+	return &api
+}
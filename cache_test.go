@@ -0,0 +1,109 @@
+package doc
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseCache(t *testing.T) {
+	dir := t.TempDir()
+	defer SetCacheDir("")
+	SetCacheDir(dir)
+	SetBuildTags()
+
+	pkgName := "github.com/uccu/go-doc/testdata/cache"
+	modelName := pkgName + "/model"
+
+	// 文件变化前的缓存在写入时删除
+	key := defaultLoader().cacheKey(modelName, pkgDir(modelName))
+	stale := filepath.Join(dir, key[:strings.Index(key, "-")]+"-stale.json")
+	os.WriteFile(stale, []byte("{}"), 0644)
+
+	first, _ := json.Marshal(NewSSDoc(SSDocInfo{}, nil).AddPacakges(pkgName))
+	if GetPkg(pkgName).cache != nil {
+		t.Fatal("package loaded from an empty cache")
+	}
+
+	SetBuildTags()
+	second, _ := json.Marshal(NewSSDoc(SSDocInfo{}, nil).AddPacakges(pkgName))
+	if GetPkg(pkgName).cache == nil {
+		t.Fatal("package not loaded from cache")
+	}
+	if GetPkg(modelName).cache == nil {
+		t.Error("imported package not loaded from cache")
+	}
+	if _, err := os.Stat(stale); err == nil {
+		t.Error("stale cache not removed")
+	}
+	if string(first) != string(second) {
+		t.Errorf("cached output differs:\n%s\n%s", first, second)
+	}
+}
+
+func TestCacheAnnotations(t *testing.T) {
+	defer SetCacheDir("")
+	SetCacheDir(t.TempDir())
+	SetBuildTags()
+
+	pkgName := "github.com/uccu/go-doc/testdata/cache"
+	NewSSDoc(SSDocInfo{}, nil).AddPacakges(pkgName)
+
+	// 注册新的注解后不再使用之前的缓存
+	RegisterAnnotation("CacheProbe", func(*DocApi, *AnnotationContext, []string) bool { return true })
+	defer func() {
+		handlersLock.Lock()
+		delete(handlers, "CacheProbe")
+		handlersLock.Unlock()
+	}()
+	SetBuildTags()
+	NewSSDoc(SSDocInfo{}, nil).AddPacakges(pkgName)
+	if GetPkg(pkgName).cache != nil {
+		t.Error("cache used after an annotation was registered")
+	}
+}
+
+func TestCacheDependency(t *testing.T) {
+	defer SetCacheDir("")
+	SetCacheDir(t.TempDir())
+	SetBuildTags()
+
+	dir, err := os.MkdirTemp("testdata", "cachedep")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	pkgName := "github.com/uccu/go-doc/testdata/" + filepath.Base(dir)
+
+	os.Mkdir(filepath.Join(dir, "model"), os.ModePerm)
+	writeModel := func(name string) {
+		os.WriteFile(filepath.Join(dir, "model", "model.go"), []byte("package model\n\ntype "+name+" struct {\n\tName string `json:\"name\"`\n}\n"), 0644)
+	}
+	writeModel("User")
+	os.WriteFile(filepath.Join(dir, "api.go"), []byte(`package api
+
+import "`+pkgName+`/model"
+
+// @Router /user
+// @Body model.User
+// @Success 200 data model.User
+func Get() {}
+`), 0644)
+
+	if err := NewSSDoc(SSDocInfo{}, nil).Strict(true).AddPacakges(pkgName).Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 依赖的包中类型改名后, 不能使用之前的缓存
+	writeModel("Person")
+	SetBuildTags()
+	ssdoc := NewSSDoc(SSDocInfo{}, nil).Strict(true).AddPacakges(pkgName)
+	if GetPkg(pkgName).cache != nil {
+		t.Error("cache used after a dependency changed")
+	}
+	if err, ok := ssdoc.Err().(DiagnosticError); !ok || len(err) != 2 {
+		t.Errorf("strict error = %v", ssdoc.Err())
+	}
+}
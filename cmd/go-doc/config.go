@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	doc "github.com/uccu/go-doc"
+)
+
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(s string) error {
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
+
+type serverFlag map[doc.SSDocServerId]*doc.SSDocServer
+
+func (s serverFlag) String() string {
+	return ""
+}
+
+// id=url[,description]
+func (s serverFlag) Set(v string) error {
+	kv := strings.SplitN(v, "=", 2)
+	if len(kv) != 2 {
+		return fmt.Errorf("invalid server %q, want id=url", v)
+	}
+	url := strings.SplitN(kv[1], ",", 2)
+	server := &doc.SSDocServer{Url: url[0]}
+	if len(url) > 1 {
+		server.Description = url[1]
+	}
+	s[doc.SSDocServerId(kv[0])] = server
+	return nil
+}
+
+type confFlags struct {
+	config  string
+	dir     string
+	pkgs    listFlag
+	exclude listFlag
+	tags    listFlag
+	servers serverFlag
+	conf    doc.DocConf
+}
+
+func addConfFlags(fs *flag.FlagSet) *confFlags {
+	c := &confFlags{servers: serverFlag{}}
+	fs.StringVar(&c.config, "config", "", "configuration file, default go-doc.yaml or go-doc.json in the module root")
+	fs.StringVar(&c.dir, "C", "", "change to `dir` before loading packages")
+	fs.Var(&c.pkgs, "pkg", "package paths or patterns such as ./... (repeatable, comma separated)")
+	fs.Var(&c.exclude, "exclude", "import path globs to skip (repeatable, comma separated)")
+	fs.Var(&c.tags, "tags", "build tags")
+	fs.Var(c.servers, "server", "server as id=url[,description] (repeatable)")
+	fs.StringVar(&c.conf.SSDocInfo.Title, "title", "", "document title")
+	fs.StringVar(&c.conf.SSDocInfo.Description, "desc", "", "document description")
+	fs.StringVar(&c.conf.SSDocInfo.Version, "version", "", "document version")
+	fs.StringVar(&c.conf.GOOS, "goos", "", "target GOOS")
+	fs.StringVar(&c.conf.GOARCH, "goarch", "", "target GOARCH")
+	fs.IntVar(&c.conf.Workers, "workers", 0, "number of packages parsed concurrently")
+	fs.StringVar(&c.conf.CacheDir, "cache", "", "parse cache directory")
+	fs.BoolVar(&c.conf.Strict, "strict", false, "fail on unresolved references")
+	return c
+}
+
+// 配置文件为基础, 命令行中设置过的参数覆盖配置文件
+func (c *confFlags) load(flags *flag.FlagSet) (doc.DocConf, error) {
+	conf := doc.DocConf{}
+	if c.dir != "" {
+		if err := os.Chdir(c.dir); err != nil {
+			return conf, err
+		}
+	}
+	var err error
+	if c.config != "" {
+		conf, err = doc.ReadConf(c.config)
+	} else if conf, err = doc.LoadConf(""); errors.Is(err, fs.ErrNotExist) {
+		err = nil
+	}
+	if err != nil {
+		return conf, err
+	}
+
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "pkg":
+			conf.Pkgs = c.pkgs
+		case "exclude":
+			conf.Exclude = c.exclude
+		case "tags":
+			conf.Tags = c.tags
+		case "server":
+			if conf.Server == nil {
+				conf.Server = make(map[doc.SSDocServerId]*doc.SSDocServer)
+			}
+			for k, v := range c.servers {
+				conf.Server[k] = v
+			}
+		case "title":
+			conf.SSDocInfo.Title = c.conf.SSDocInfo.Title
+		case "desc":
+			conf.SSDocInfo.Description = c.conf.SSDocInfo.Description
+		case "version":
+			conf.SSDocInfo.Version = c.conf.SSDocInfo.Version
+		case "goos":
+			conf.GOOS = c.conf.GOOS
+		case "goarch":
+			conf.GOARCH = c.conf.GOARCH
+		case "workers":
+			conf.Workers = c.conf.Workers
+		case "cache":
+			conf.CacheDir = c.conf.CacheDir
+		case "strict":
+			conf.Strict = c.conf.Strict
+		}
+	})
+
+	if len(conf.Pkgs) == 0 {
+		conf.Pkgs = []string{"./..."}
+	}
+	return conf, nil
+}
+
+func printDiagnostics(diags []*doc.Diagnostic) {
+	for _, d := range diags {
+		fmt.Fprintln(os.Stderr, d)
+	}
+}
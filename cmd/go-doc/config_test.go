@@ -0,0 +1,96 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestServerFlag(t *testing.T) {
+	tests := []struct {
+		value, url, desc string
+		err              bool
+	}{
+		{"api=http://localhost:8080", "http://localhost:8080", "", false},
+		{"api=http://localhost,本地, 测试", "http://localhost", "本地, 测试", false},
+		{"api", "", "", true},
+	}
+	for _, tt := range tests {
+		s := serverFlag{}
+		err := s.Set(tt.value)
+		if (err != nil) != tt.err {
+			t.Errorf("%s: err = %v", tt.value, err)
+			continue
+		}
+		if tt.err {
+			continue
+		}
+		if v := s["api"]; v == nil || v.Url != tt.url || v.Description != tt.desc {
+			t.Errorf("%s: server = %+v", tt.value, v)
+		}
+	}
+}
+
+func TestConfFlags(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "go-doc.yaml")
+	os.WriteFile(file, []byte(`info:
+  title: 文件
+  version: v1
+servers:
+  api:
+    url: http://localhost
+packages: [./api/...]
+tags: [prod]
+strict: true
+`), 0644)
+	empty := filepath.Join(dir, "empty.yaml")
+	os.WriteFile(empty, []byte("info:\n  title: 空\n"), 0644)
+
+	tests := []struct {
+		name    string
+		args    []string
+		title   string
+		version string
+		pkgs    string
+		tags    string
+		servers string
+		strict  bool
+	}{
+		{"config only", []string{"-config", file}, "文件", "v1", "./api/...", "prod", "api", true},
+		{
+			"flags override",
+			[]string{"-config", file, "-title", "命令行", "-strict=false", "-pkg", "./a,./b", "-tags", "dev", "-server", "web=http://web"},
+			"命令行", "v1", "./a,./b", "dev", "api,web", false,
+		},
+		// 没有设置的参数不覆盖配置文件, 即使是零值
+		{"unset flags", []string{"-config", file, "-desc", "说明"}, "文件", "v1", "./api/...", "prod", "api", true},
+		{"default packages", []string{"-config", empty}, "空", "", "./...", "", "", false},
+	}
+	for _, tt := range tests {
+		fs := flag.NewFlagSet(tt.name, flag.ContinueOnError)
+		c := addConfFlags(fs)
+		if err := fs.Parse(tt.args); err != nil {
+			t.Fatal(err)
+		}
+		conf, err := c.load(fs)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+
+		servers := []string{}
+		for id := range conf.Server {
+			servers = append(servers, string(id))
+		}
+		sort.Strings(servers)
+		got := []string{conf.SSDocInfo.Title, conf.SSDocInfo.Version, strings.Join(conf.Pkgs, ","), strings.Join(conf.Tags, ","), strings.Join(servers, ",")}
+		want := []string{tt.title, tt.version, tt.pkgs, tt.tags, tt.servers}
+		if strings.Join(got, " | ") != strings.Join(want, " | ") || conf.Strict != tt.strict {
+			t.Errorf("%s: conf = %q strict %v, want %q strict %v", tt.name, got, conf.Strict, want, tt.strict)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	doc "github.com/uccu/go-doc"
+)
+
+// 比较两份 doc.json, 有差异时返回1
+func diff(args []string) int {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: go-doc diff old.json new.json")
+	}
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 2
+	}
+
+	old, err := loadDoc(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	cur, err := loadDoc(fs.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	lines := diffDoc(old, cur)
+	for _, l := range lines {
+		fmt.Println(l)
+	}
+	if len(lines) > 0 {
+		return 1
+	}
+	return 0
+}
+
+func loadDoc(file string) (*doc.SSDoc, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	ssdoc, err := doc.Load(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	return ssdoc, nil
+}
+
+func apiKeys(ssdoc *doc.SSDoc) map[string]*doc.SSDocApi {
+	m := make(map[string]*doc.SSDocApi)
+	for _, apis := range ssdoc.Apis {
+		for _, api := range apis {
+			for _, method := range api.Method {
+				key := strings.ToUpper(method) + " " + api.Path
+				if api.Type != "" && api.Type != "http" {
+					key = api.Type + " " + key
+				}
+				if api.Server != "" {
+					key += " (" + string(api.Server) + ")"
+				}
+				m[key] = api
+			}
+		}
+	}
+	return m
+}
+
+func diffDoc(old, cur *doc.SSDoc) []string {
+	lines := []string{}
+	a, b := apiKeys(old), apiKeys(cur)
+
+	keys := []string{}
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		x, inOld := a[k]
+		y, inNew := b[k]
+		switch {
+		case !inOld:
+			lines = append(lines, "+ "+k+" "+y.Name)
+		case !inNew:
+			lines = append(lines, "- "+k+" "+x.Name)
+		default:
+			if changed := changedFields(x, y); len(changed) > 0 {
+				lines = append(lines, "~ "+k+" "+y.Name+" ("+strings.Join(changed, ", ")+")")
+			}
+		}
+	}
+	return lines
+}
+
+func changedFields(x, y *doc.SSDocApi) []string {
+	a, b := fieldsJson(x), fieldsJson(y)
+	keys := []string{}
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	changed := []string{}
+	for _, k := range keys {
+		if !bytes.Equal(a[k], b[k]) {
+			changed = append(changed, k)
+		}
+	}
+	return changed
+}
+
+func fieldsJson(api *doc.SSDocApi) map[string]json.RawMessage {
+	m := make(map[string]json.RawMessage)
+	b, _ := json.Marshal(api)
+	json.Unmarshal(b, &m)
+	return m
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	doc "github.com/uccu/go-doc"
+)
+
+func TestDiffDoc(t *testing.T) {
+	newDoc := func(apis ...*doc.SSDocApi) *doc.SSDoc {
+		ssdoc := doc.NewSSDoc(doc.SSDocInfo{}, nil)
+		ssdoc.Apis["default"] = apis
+		return ssdoc
+	}
+	user := &doc.SSDocApi{Name: "用户", Path: "/user", Method: []string{"get"}}
+	order := &doc.SSDocApi{Name: "订单", Path: "/order", Method: []string{"post"}, Tag: []string{"order"}}
+
+	tests := []struct {
+		name     string
+		old, cur *doc.SSDoc
+		want     []string
+	}{
+		{"same", newDoc(user, order), newDoc(user, order), nil},
+		{"added", newDoc(user), newDoc(user, order), []string{"+ POST /order 订单"}},
+		{"removed", newDoc(user, order), newDoc(order), []string{"- GET /user 用户"}},
+		{
+			"changed",
+			newDoc(user, order),
+			newDoc(user, &doc.SSDocApi{Name: "订单", Path: "/order", Method: []string{"post"}, Description: "下单", Tag: []string{"trade"}}),
+			[]string{"~ POST /order 订单 (description, tag)"},
+		},
+		{
+			"method",
+			newDoc(user),
+			newDoc(&doc.SSDocApi{Name: "用户", Path: "/user", Method: []string{"get", "post"}}),
+			[]string{"~ GET /user 用户 (method)", "+ POST /user 用户"},
+		},
+		{
+			"websocket and server",
+			newDoc(),
+			newDoc(&doc.SSDocApi{Name: "推送", Path: "/ws", Method: []string{"get"}, Type: "websocket", Server: "push"}),
+			[]string{"+ websocket GET /ws (push) 推送"},
+		},
+	}
+	for _, tt := range tests {
+		if got := diffDoc(tt.old, tt.cur); strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%s: diff = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	doc "github.com/uccu/go-doc"
+)
+
+func generate(args []string) int {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	c := addConfFlags(fs)
+	out := fs.String("out", "", "output directory (default doc)")
+	formats := listFlag{}
+	fs.Var(&formats, "format", "output formats: json, openapi, markdown, go (comma separated)")
+	pkgName := fs.String("package", "", "package name of the generated Go file")
+	fs.Parse(args)
+
+	// -out 相对于执行目录, 不受 -C 影响
+	if *out != "" && !filepath.IsAbs(*out) {
+		wd, _ := os.Getwd()
+		*out = filepath.Join(wd, *out)
+	}
+
+	conf, err := c.load(fs)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if len(formats) == 0 {
+		formats = conf.Formats
+	}
+	if len(formats) == 0 {
+		formats = listFlag{"json"}
+	}
+	if *out == "" {
+		*out = conf.Output
+	}
+	if *out == "" {
+		*out = "doc"
+	}
+
+	d := doc.New(conf)
+	printDiagnostics(d.Diagnostics())
+	if err := d.Err(); err != nil {
+		return 1
+	}
+
+	ssdoc := d.SSDoc()
+	if err := os.MkdirAll(*out, os.ModePerm); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	for _, f := range formats {
+		var b []byte
+		var name string
+		switch f {
+		case "json":
+			name = "doc.json"
+			b, err = json.Marshal(ssdoc)
+		case "openapi":
+			name = "openapi.json"
+			b, err = json.MarshalIndent(ssdoc.OpenAPI(), "", "  ")
+		case "markdown", "md":
+			name = "doc.md"
+			b = ssdoc.Markdown()
+		case "go":
+			if err := ssdoc.Generate(*out, *pkgName); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+			continue
+		default:
+			err = fmt.Errorf("unknown format %s", f)
+		}
+		if err == nil {
+			err = os.WriteFile(filepath.Join(*out, name), b, 0644)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	doc "github.com/uccu/go-doc"
+)
+
+// 输出所有诊断, 存在错误时返回1
+func lint(args []string) int {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	c := addConfFlags(fs)
+	fs.Parse(args)
+
+	conf, err := c.load(fs)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	conf.Strict = true
+
+	d := doc.New(conf)
+	printDiagnostics(d.Diagnostics())
+	if d.Err() != nil {
+		return 1
+	}
+	return 0
+}
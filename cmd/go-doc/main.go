@@ -0,0 +1,54 @@
+// mock 使用 Go 1.22 的路由规则, 如 GET /user/{id}
+//go:debug httpmuxgo121=0
+
+// go-doc 根据接口注解生成文档
+//
+//	go-doc generate [flags]            生成 doc.json/OpenAPI/Markdown
+//	go-doc serve [flags]               启动文档页面
+//	go-doc lint [flags]                检查注解
+//	go-doc diff old.json new.json      比较两份文档
+//	go-doc mock [flags]                按文档启动模拟接口
+//
+// 生成编译进程序的文档:
+//
+//	//go:generate go run github.com/uccu/go-doc/cmd/go-doc generate -format go -out .
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+var commands = map[string]func(args []string) int{
+	"generate": generate,
+	"serve":    serve,
+	"lint":     lint,
+	"diff":     diff,
+	"mock":     mock,
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `usage: go-doc <command> [flags]
+
+commands:
+  generate  write doc.json, OpenAPI or Markdown
+  serve     serve the documentation UI
+  lint      report annotation problems
+  diff      compare two doc.json files
+  mock      serve mock responses
+
+run "go-doc <command> -h" for the flags of a command`)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+	os.Exit(cmd(os.Args[2:]))
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	doc "github.com/uccu/go-doc"
+)
+
+// 按文档中的第一个成功返回生成模拟接口
+func mock(args []string) int {
+	fs := flag.NewFlagSet("mock", flag.ExitOnError)
+	c := addConfFlags(fs)
+	addr := fs.String("addr", ":7001", "listen address")
+	file := fs.String("doc", "", "doc.json to mock instead of parsing packages")
+	fs.Parse(args)
+
+	var ssdoc *doc.SSDoc
+	if *file != "" {
+		var err error
+		if ssdoc, err = loadDoc(*file); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	} else {
+		conf, err := c.load(fs)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		d := doc.New(conf)
+		printDiagnostics(d.Diagnostics())
+		ssdoc = d.SSDoc()
+	}
+
+	fmt.Fprintf(os.Stderr, "mock addr : http://%s/\n", *addr)
+	if err := http.ListenAndServe(*addr, mockHandler(ssdoc)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func mockHandler(ssdoc *doc.SSDoc) http.Handler {
+	mux := http.NewServeMux()
+	for _, apis := range ssdoc.Apis {
+		for _, api := range apis {
+			if api.Type != "" && api.Type != "http" {
+				continue
+			}
+			code, body := api.Mock()
+			js, _ := json.Marshal(body)
+			h := func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(code)
+				w.Write(js)
+			}
+			for _, m := range api.Method {
+				handle(mux, strings.ToUpper(m)+" "+doc.MuxPattern(api.Path), h)
+			}
+		}
+	}
+	return mux
+}
+
+// 重复或冲突的路由只跳过, 不影响其它接口
+func handle(mux *http.ServeMux, pattern string, h http.HandlerFunc) {
+	defer func() {
+		if err := recover(); err != nil {
+			fmt.Fprintln(os.Stderr, "skip", pattern+":", err)
+		}
+	}()
+	mux.HandleFunc(pattern, h)
+}
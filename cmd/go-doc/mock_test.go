@@ -0,0 +1,39 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	doc "github.com/uccu/go-doc"
+)
+
+func TestMockHandler(t *testing.T) {
+	ssdoc := doc.NewSSDoc(doc.SSDocInfo{}, nil)
+	ssdoc.Apis["default"] = []*doc.SSDocApi{
+		{Path: "/user/:id", Method: []string{"get"}},
+		{Path: "/files/*path", Method: []string{"get"}},
+		{Path: "/order/{oid}", Method: []string{"post"}},
+		{Path: "/ws", Method: []string{"get"}, Type: "websocket"},
+	}
+	h := mockHandler(ssdoc)
+
+	tests := []struct {
+		method, path string
+		code         int
+	}{
+		{"GET", "/user/1", 200},
+		{"GET", "/user/1/x", 404},
+		{"GET", "/files/a", 200},
+		{"GET", "/files/a/b/c.txt", 200},
+		{"POST", "/order/1", 200},
+		{"GET", "/order/1", 405},
+		{"GET", "/ws", 404},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.code {
+			t.Errorf("%s %s = %d, want %d", tt.method, tt.path, w.Code, tt.code)
+		}
+	}
+}
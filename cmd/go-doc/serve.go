@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+
+	doc "github.com/uccu/go-doc"
+)
+
+func serve(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	c := addConfFlags(fs)
+	addr := fs.String("addr", ":7000", "listen address")
+	fs.Parse(args)
+
+	conf, err := c.load(fs)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	conf.Url = "/doc.json"
+
+	d := doc.New(conf)
+	printDiagnostics(d.Diagnostics())
+
+	http.HandleFunc("/doc.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		d.Json(w)
+	})
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		d.Html(w)
+	})
+	fmt.Fprintf(os.Stderr, "doc addr : http://%s/\n", *addr)
+	if err := http.ListenAndServe(*addr, nil); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
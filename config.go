@@ -0,0 +1,64 @@
+package doc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 模块根目录下按顺序查找的配置文件
+var confFiles = []string{"go-doc.yaml", "go-doc.yml", "go-doc.json"}
+
+// 读取目录下的配置文件, dir 为空时使用主模块根目录
+func LoadConf(dir string) (DocConf, error) {
+	if dir == "" {
+		setMod()
+		dir = mods.main.Dir
+	}
+	for _, name := range confFiles {
+		file := filepath.Join(dir, name)
+		if _, err := os.Stat(file); err == nil {
+			return ReadConf(file)
+		}
+	}
+	return DocConf{}, &fs.PathError{Op: "open", Path: filepath.Join(dir, confFiles[0]), Err: fs.ErrNotExist}
+}
+
+// 读取 yaml 或 json 配置文件, 其中的 ${VAR} 与 ${VAR:-默认值} 替换为环境变量
+func ReadConf(file string) (DocConf, error) {
+	c := DocConf{}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return c, err
+	}
+	b = []byte(expandEnv(string(b)))
+
+	if strings.HasSuffix(file, ".json") {
+		err = json.Unmarshal(b, &c)
+	} else {
+		err = yaml.Unmarshal(b, &c)
+	}
+	if err != nil {
+		return c, fmt.Errorf("%s: %v", file, err)
+	}
+	return c, nil
+}
+
+// 只替换 ${VAR} 与 ${VAR:-默认值}, 其它的 $ 原样保留
+var envRegexp = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+func expandEnv(s string) string {
+	return envRegexp.ReplaceAllStringFunc(s, func(m string) string {
+		sub := envRegexp.FindStringSubmatch(m)
+		if v := os.Getenv(sub[1]); v != "" || sub[2] == "" {
+			return v
+		}
+		return sub[3]
+	})
+}
@@ -0,0 +1,68 @@
+package doc
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestReadConf(t *testing.T) {
+	t.Setenv("DOC_TITLE", "订单")
+	t.Setenv("API_URL", "")
+
+	c, err := ReadConf("testdata/conf/go-doc.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.SSDocInfo.Title != "订单" || c.SSDocInfo.Version != "v1" || c.Server["api"].Url != "http://localhost:8080" {
+		t.Errorf("conf = %+v", c)
+	}
+	if len(c.Formats) != 2 || !c.Strict || len(c.Headers) != 1 || !c.Headers[0].Required {
+		t.Errorf("conf = %+v", c)
+	}
+
+	if c.SSDocInfo.Description != "单价 $5, 模板 $1 与 $HOME 原样保留" {
+		t.Errorf("description = %q", c.SSDocInfo.Description)
+	}
+
+	js, _ := json.Marshal(c)
+	var j DocConf
+	if err := json.Unmarshal(js, &j); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := json.Marshal(j); string(again) != string(js) {
+		t.Errorf("json conf differs:\n%s\n%s", js, again)
+	}
+}
+
+func TestConfOverride(t *testing.T) {
+	c, err := ReadConf("testdata/conf/go-doc.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ssdoc := NewSSDoc(c.SSDocInfo, c.Server).Strict(c.Strict).Override(c.Types).Header(c.Headers...).AddPacakges(c.Pkgs...)
+	if err := ssdoc.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	apis := ssdoc.Apis["default"]
+	if len(apis) != 2 {
+		t.Fatalf("apis = %d", len(apis))
+	}
+	if h := apis[0].Header; len(h) != 1 || h[0].Description != "覆盖" {
+		t.Errorf("declared header replaced: %+v", h[0])
+	}
+	if h := apis[1].Header; len(h) != 1 || h[0].Name != "Authorization" || !h[0].Required {
+		t.Errorf("global header missing: %+v", h)
+	}
+
+	fields := fieldsOf(apis[1].Success[0].Value)
+	if len(fields) != 3 {
+		t.Fatalf("fields = %d", len(fields))
+	}
+	if f := elemOf(fields[1]); f.Type != FloatType || f.TypeName != "float64" {
+		t.Errorf("price = %+v", f.SSDocType)
+	}
+	if f := fields[2]; f.Type != StringType || f.TypeName != "string" {
+		t.Errorf("createdAt = %+v", f.SSDocType)
+	}
+}
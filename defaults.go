@@ -0,0 +1,159 @@
+package doc
+
+import (
+	"go/ast"
+	"path/filepath"
+	"strings"
+)
+
+// 没有注解时接口的默认值
+const (
+	DefaultMethod = "post"
+	DefaultAccept = "json"
+	DefaultType   = "http"
+)
+
+// 可以写在包注释或文件开头注释中的注解, 作为包内或文件内接口的默认值.
+// 包注释为 doc.go 中的, 或以 "Package 包名" 开头的注释, 其它文件紧挨 package 的注释只是文件开头的注释.
+// 文件开头的注释优先于包注释, 函数注释中的 @Tag, @Header 与 @Use 追加到默认值后, 其它注解覆盖默认值
+var defaultAnnotations = map[string]bool{
+	"Category": true,
+	"Server":   true,
+	"Type":     true,
+	"Tag":      true,
+	"Tags":     true,
+	"Header":   true,
+	"Use":      true,
+	"Prefix":   true,
+	"Accept":   true,
+	"Method":   true,
+}
+
+func newDefaults(pkg *Pkg, file string) *DocApi {
+	return &DocApi{
+		Accept: []string{DefaultAccept},
+		Method: []string{DefaultMethod},
+		Type:   DefaultType,
+		pkg:    pkg,
+		file:   file,
+	}
+}
+
+// 文件内接口的默认值, 由包注释与文件开头的注释得到
+func (pkg *Pkg) defaults(file string) *DocApi {
+	pkg.defaultsOnce.Do(pkg.setDefaults)
+	if d, ok := pkg.fileDefaults[file]; ok {
+		return d
+	}
+	return pkg.pkgDefaults
+}
+
+func (pkg *Pkg) setDefaults() {
+	pkg.pkgDefaults = newDefaults(pkg, "")
+	pkg.fileDefaults = make(map[string]*DocApi)
+	if pkg.pkg == nil {
+		return
+	}
+
+	files := pkg.Files()
+	docFile := pkg.docFile()
+	if docFile != "" {
+		pkg.pkgDefaults.file = docFile
+		pkg.pkgDefaults.parseDefaults(pkg.pkg.Files[docFile].Doc)
+	}
+	for _, file := range files {
+		f := pkg.pkg.Files[file]
+		var d *DocApi
+		for _, cg := range f.Comments {
+			if (file == docFile && cg == f.Doc) || cg.End() >= f.Package {
+				continue
+			}
+			if d == nil {
+				d = pkg.pkgDefaults.copy()
+				d.file = file
+			}
+			d.parseDefaults(cg)
+		}
+		if d != nil {
+			pkg.fileDefaults[file] = d
+		}
+	}
+}
+
+// 包注释所在的文件, 优先 doc.go
+func (pkg *Pkg) docFile() string {
+	files := pkg.Files()
+	for _, file := range files {
+		if filepath.Base(file) == "doc.go" && pkg.pkg.Files[file].Doc != nil {
+			return file
+		}
+	}
+	for _, file := range files {
+		if f := pkg.pkg.Files[file]; f.Doc != nil {
+			if words := strings.Fields(f.Doc.Text()); len(words) > 1 && words[0] == "Package" && words[1] == pkg.Name {
+				return file
+			}
+		}
+	}
+	return ""
+}
+
+func (doc *DocApi) parseDefaults(comments *ast.CommentGroup) {
+	for _, a := range ParseAnnotations(comments) {
+		if !defaultAnnotations[a.Name] {
+			continue
+		}
+		doc.pos = a.Pos
+		doc.annotation = a.Name
+		n := len(doc.diags)
+		if !doc.parse(a.Name, a.Args) && len(doc.diags) == n {
+			doc.report(SeverityError, "invalid arguments: %s", a.Text)
+		}
+	}
+	doc.pos = 0
+	doc.annotation = ""
+}
+
+// 复制默认值, 切片在追加时不影响其它接口
+func (doc *DocApi) copy() *DocApi {
+	c := *doc
+	c.Tag = c.Tag[:len(c.Tag):len(c.Tag)]
+	c.Header = c.Header[:len(c.Header):len(c.Header)]
+	c.Use = c.Use[:len(c.Use):len(c.Use)]
+	c.diags = c.diags[:len(c.diags):len(c.diags)]
+	c.positions = nil
+	return &c
+}
+
+func (doc *DocApi) ParsePrefix(s []string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	doc.prefix = s[0]
+	return true
+}
+
+// 同名请求头只保留最后声明的一个, 函数注释中的请求头覆盖默认值
+func uniqueHeaders(list []*DocHeader) []*DocHeader {
+	headers := []*DocHeader{}
+	for _, h := range list {
+		replaced := false
+		for i, v := range headers {
+			if strings.EqualFold(v.Name, h.Name) {
+				headers[i], replaced = h, true
+			}
+		}
+		if !replaced {
+			headers = append(headers, h)
+		}
+	}
+	return headers
+}
+
+// 路由加上前缀, 前缀与路由之间只保留一个 /
+func withPrefix(prefix, router string) string {
+	if prefix == "" || router == "" {
+		return router
+	}
+	return strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(router, "/")
+}
@@ -0,0 +1,163 @@
+package doc
+
+import "strings"
+
+// 包内可复用的返回与请求头, 在包注释或任意注释中声明:
+// @DefineFail...		name code KEY Type [desc="描述"]
+// @DefineHeader...	name KEY required 备注
+// 接口中用 @FailRef name 与 @HeaderRef name 引用, 其它包的定义写成 pkg.name
+type DocDefines struct {
+	Fail   map[string][]*DocRet
+	Header map[string][]*DocHeader
+	diags  []*Diagnostic
+}
+
+var defineAnnotations = map[string]bool{
+	"DefineFail":   true,
+	"DefineHeader": true,
+}
+
+func (pkg *Pkg) Defines() *DocDefines {
+	pkg.definesOnce.Do(pkg.setDefines)
+	return pkg.defines
+}
+
+func (pkg *Pkg) setDefines() {
+	pkg.defines = &DocDefines{
+		Fail:   make(map[string][]*DocRet),
+		Header: make(map[string][]*DocHeader),
+	}
+	if pkg.cache != nil {
+		pkg.decodeDefines(pkg.cache.Defines)
+		return
+	}
+
+	for _, file := range pkg.Files() {
+		for _, cg := range pkg.pkg.Files[file].Comments {
+			for _, a := range ParseAnnotations(cg) {
+				if defineAnnotations[a.Name] {
+					pkg.defines.parse(a, pkg, file)
+				}
+			}
+		}
+	}
+}
+
+func (d *DocDefines) parse(a *Annotation, pkg *Pkg, file string) {
+	api := &DocApi{pkg: pkg, file: file, pos: a.Pos, annotation: a.Name}
+	ok := len(a.Args) > 1
+	if ok {
+		name := a.Args[0]
+		switch a.Name {
+		case "DefineFail":
+			if ok = api.ParseFail(a.Args[1:]); ok {
+				d.Fail[name] = append(d.Fail[name], api.Fail...)
+			}
+		case "DefineHeader":
+			if ok = api.ParseHeader(a.Args[1:]); ok {
+				d.Header[name] = append(d.Header[name], api.Header...)
+			}
+		}
+	}
+	if !ok && len(api.diags) == 0 {
+		api.report(SeverityError, "invalid arguments: %s", a.Text)
+	}
+	d.diags = append(d.diags, api.diags...)
+}
+
+func (doc *DocApi) ParseFailRef(s []string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	doc.FailRef = append(doc.FailRef, splitList(s)...)
+	return true
+}
+
+func (doc *DocApi) ParseHeaderRef(s []string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	doc.HeaderRef = append(doc.HeaderRef, splitList(s)...)
+	return true
+}
+
+// 引用的定义所在的包, pkg.name 为导入的包中的定义
+func (doc *DocApi) defines(ref string) (*DocDefines, string) {
+	pkg := doc.pkg
+	if i := strings.LastIndex(ref, "."); i >= 0 {
+		pkg = pkg.GetPkg(doc.file, ref[:i])
+		ref = ref[i+1:]
+	}
+	if pkg == nil {
+		return nil, ref
+	}
+	return pkg.Defines(), ref
+}
+
+// 展开 @FailRef 与 @HeaderRef, 接口中已声明的同名请求头与相同状态码和 KEY 的返回优先
+func (doc *SSDoc) expandRefs(i *DocApi) ([]*DocRet, []*DocHeader) {
+	fail, header := i.Fail, i.Header
+	if i.pkg == nil {
+		return fail, header
+	}
+
+	undefined := func(annotation, kind, ref string) {
+		doc.report(&Diagnostic{
+			Severity:   SeverityError,
+			Message:    "undefined " + kind + " set " + ref,
+			Annotation: annotation,
+			Pos:        i.Position(annotation),
+		})
+	}
+
+	for _, ref := range i.FailRef {
+		d, name := i.defines(ref)
+		if d != nil {
+			doc.report(d.diags...)
+		}
+		if d == nil || d.Fail[name] == nil {
+			undefined("FailRef", "fail", ref)
+			continue
+		}
+		for _, r := range d.Fail[name] {
+			if !hasRet(fail, r) {
+				fail = append(fail[:len(fail):len(fail)], r)
+			}
+		}
+	}
+
+	for _, ref := range i.HeaderRef {
+		d, name := i.defines(ref)
+		if d != nil {
+			doc.report(d.diags...)
+		}
+		if d == nil || d.Header[name] == nil {
+			undefined("HeaderRef", "header", ref)
+			continue
+		}
+		for _, h := range d.Header[name] {
+			if !hasHeader(header, h.Name) {
+				header = append(header[:len(header):len(header)], h)
+			}
+		}
+	}
+	return fail, header
+}
+
+func hasRet(list []*DocRet, r *DocRet) bool {
+	for _, v := range list {
+		if v.Code == r.Code && v.Key == r.Key {
+			return true
+		}
+	}
+	return false
+}
+
+func hasHeader(list []*DocHeader, name string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v.Name, name) {
+			return true
+		}
+	}
+	return false
+}
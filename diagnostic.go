@@ -0,0 +1,79 @@
+package doc
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+)
+
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	if s == SeverityWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Severity) UnmarshalText(b []byte) error {
+	*s = SeverityError
+	if string(b) == "warning" {
+		*s = SeverityWarning
+	}
+	return nil
+}
+
+type Diagnostic struct {
+	Severity   Severity       `json:"severity"`
+	Message    string         `json:"message"`
+	Annotation string         `json:"annotation,omitempty"` // 注解名, 不含@
+	Pos        token.Position `json:"pos"`
+}
+
+func (d *Diagnostic) String() string {
+	str := d.Pos.String()
+	if str == "-" {
+		str = ""
+	} else {
+		str += ": "
+	}
+	str += d.Severity.String() + ": "
+	if d.Annotation != "" {
+		str += "@" + d.Annotation + ": "
+	}
+	return str + d.Message
+}
+
+func newDiagnostic(sev Severity, pos token.Pos, file, annotation, format string, a ...interface{}) *Diagnostic {
+	d := &Diagnostic{
+		Severity:   sev,
+		Message:    fmt.Sprintf(format, a...),
+		Annotation: annotation,
+	}
+	if pos.IsValid() {
+		d.Pos = fset.Position(pos)
+	} else {
+		d.Pos.Filename = file
+	}
+	return d
+}
+
+// 严格模式下, 存在错误级别的诊断时返回
+type DiagnosticError []*Diagnostic
+
+func (e DiagnosticError) Error() string {
+	list := make([]string, 0, len(e))
+	for _, d := range e {
+		list = append(list, d.String())
+	}
+	return strings.Join(list, "\n")
+}
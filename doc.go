@@ -3,9 +3,6 @@ package doc
 import (
 	"encoding/json"
 	"net/http"
-	"path"
-	"runtime"
-	"text/template"
 )
 
 type doc struct {
@@ -15,11 +12,29 @@ type doc struct {
 }
 
 type DocConf struct {
-	SSDocInfo SSDocInfo
-	Server    map[SSDocServerId]*SSDocServer
-	Pkgs      []string
-	Url       string
-	Name      string
+	SSDocInfo   SSDocInfo                      `json:"info" yaml:"info"`
+	Server      map[SSDocServerId]*SSDocServer `json:"servers" yaml:"servers"`
+	Pkgs        []string                       `json:"packages" yaml:"packages"` // 包路径, 支持 ./... 形式
+	Exclude     []string                       `json:"exclude" yaml:"exclude"`   // 忽略的包
+	Url         string                         `json:"url" yaml:"url"`           // 页面读取 doc.json 的地址
+	Tags        []string                       `json:"tags" yaml:"tags"`         // 编译 tag
+	GOOS        string                         `json:"goos" yaml:"goos"`
+	GOARCH      string                         `json:"goarch" yaml:"goarch"`
+	Workers     int                            `json:"workers" yaml:"workers"`         // 同时解析的包数量
+	CacheDir    string                         `json:"cacheDir" yaml:"cacheDir"`       // 解析缓存目录
+	Strict      bool                           `json:"strict" yaml:"strict"`           // 存在错误时失败
+	Formats     []string                       `json:"formats" yaml:"formats"`         // 输出格式 json/openapi/markdown
+	Output      string                         `json:"output" yaml:"output"`           // 输出目录
+	Types       map[string]string              `json:"types" yaml:"types"`             // 类型替换, 如 time.Time: string
+	Headers     []*SSDocHeader                 `json:"headers" yaml:"headers"`         // 所有接口共用的请求头
+	Envelope    EnvelopeConf                   `json:"envelope" yaml:"envelope"`       // 返回内容外层的结构
+	Middlewares map[string]*MiddlewareConf     `json:"middlewares" yaml:"middlewares"` // 中间件, 接口中用 @Use 引用
+}
+
+// 返回内容外层的结构, 如 {code, message, data}
+type EnvelopeConf struct {
+	Type  string `json:"type" yaml:"type"`   // 类型的完整路径, 如 github.com/x/api.Response
+	Field string `json:"field" yaml:"field"` // 放置返回内容的字段的 json 名字, 默认 data
 }
 
 func (d *doc) Json(w http.ResponseWriter) *doc {
@@ -28,23 +43,46 @@ func (d *doc) Json(w http.ResponseWriter) *doc {
 }
 
 func (d *doc) Html(w http.ResponseWriter) *doc {
-	_, file, _, ok := runtime.Caller(0)
-	if ok {
-		t, _ := template.New("index.html").ParseFiles(path.Dir(file) + "/index.html")
-		t.Execute(w, d.def)
-	}
+	indexTemplate.Execute(w, d.def)
 	return d
 }
 
+func (d *doc) SSDoc() *SSDoc {
+	return d.ssdoc
+}
+
+func (d *doc) Err() error {
+	return d.ssdoc.Err()
+}
+
+func (d *doc) Diagnostics() []*Diagnostic {
+	return d.ssdoc.Diagnostics()
+}
+
 func New(c DocConf) *doc {
 	doc := &doc{
 		ssdoc: NewSSDoc(c.SSDocInfo, c.Server),
 		j:     make([]byte, 0),
 		def:   c.Url,
 	}
-	for _, v := range c.Pkgs {
-		doc.ssdoc.AddPacakges(c.Name, v)
+	if c.Tags != nil {
+		doc.ssdoc.BuildTags(c.Tags...)
+	}
+	if c.GOOS != "" || c.GOARCH != "" {
+		doc.ssdoc.Platform(c.GOOS, c.GOARCH)
+	}
+	if c.Workers > 0 {
+		doc.ssdoc.Workers(c.Workers)
+	}
+	if c.CacheDir != "" {
+		SetCacheDir(c.CacheDir)
+	}
+	doc.ssdoc.Strict(c.Strict).Exclude(c.Exclude...).Override(c.Types).Header(c.Headers...)
+	doc.ssdoc.Envelope(c.Envelope.Type, c.Envelope.Field)
+	for _, name := range sortedKeys(c.Middlewares) {
+		doc.ssdoc.Middleware(name, c.Middlewares[name])
 	}
+	doc.ssdoc.AddPacakges(c.Pkgs...)
 	doc.j, _ = json.Marshal(doc.ssdoc)
 	return doc
 }
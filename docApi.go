@@ -2,14 +2,15 @@ package doc
 
 import (
 	"go/ast"
-	"regexp"
+	"go/parser"
+	"go/token"
 	"strings"
 
 	"github.com/uccu/go-stringify"
 )
 
-// @Summary				名字
-// @Desc/Description	描述
+// @Summary				名字, 省略时使用函数注释的第一句
+// @Desc/Description	描述, Markdown, 可以跨多行, 为空时使用函数注释
 // @Category			分类
 // @Router				路径
 // @Type				http/ws
@@ -17,10 +18,19 @@ import (
 // @Tag					tag[,tag]
 // @Accept				json
 // @Header...			KEY required 备注
+// @Param...			name query/path/header/cookie/form type required 备注
 // @Rest				Struct
-// @Body				Struct
-// @Success...			code KEY Struct
-// @FAIL...				code KEY Struct
+// @Body				Type, 可以是 Struct, *pkg.Struct, []Struct, map[string]Struct 或 int64 等
+// @Success...			code KEY Type [desc="描述"]
+// @FAIL...				code KEY Type [desc="描述"]
+// @Order				排序, 越小越靠前
+// @NoEnvelope			返回内容不使用全局的外层结构
+// @FailRef...			name[,name], 引用 @DefineFail 定义的返回
+// @HeaderRef...		name[,name], 引用 @DefineHeader 定义的请求头
+// @Use...				name[,name], 继承中间件的请求头, 失败返回与标签
+// @Middleware			name, 声明中间件, 同一注释中的 @Header, @Fail 与 @Tag 属于该中间件
+// @Prefix				路由前缀, 一般写在包注释或文件开头的注释中
+// 其它注解可以通过 RegisterAnnotation 注册
 type DocApi struct {
 	Summary     string           `json:"summary"`
 	Description string           `json:"description"`
@@ -32,12 +42,25 @@ type DocApi struct {
 	Tag         []string         `json:"tag,omitempty"`
 	Accept      []string         `json:"accept,omitempty"`
 	Header      []*DocHeader     `json:"header,omitempty"`
+	Param       []*DocParam      `json:"param,omitempty"`
 	Rest        *TypeSpecWithKey `json:"rest,omitempty"`
 	Body        *TypeSpecWithKey `json:"body,omitempty"`
 	Success     []*DocRet        `json:"success,omitempty"`
 	Fail        []*DocRet        `json:"fail,omitempty"`
+	Order       int              `json:"order,omitempty"`
+	NoEnvelope  bool             `json:"noEnvelope,omitempty"`
+	FailRef     []string         `json:"failRef,omitempty"`
+	HeaderRef   []string         `json:"headerRef,omitempty"`
+	Use         []string         `json:"use,omitempty"`
+	Middleware  string           `json:"middleware,omitempty"`
+	Extensions  map[string]any   `json:"extensions,omitempty"`
 	pkg         *Pkg             `json:"-"`
 	file        string           `json:"-"`
+	pos         token.Pos
+	annotation  string
+	prefix      string
+	diags       []*Diagnostic
+	positions   map[string]token.Position
 }
 
 type DocHeader struct {
@@ -46,76 +69,161 @@ type DocHeader struct {
 	Remark   string
 }
 
+type DocParam struct {
+	Name     string
+	In       string
+	Value    *TypeSpecWithKey
+	Required bool
+	Remark   string
+}
+
 type DocRet struct {
-	Code  int16
-	Key   string
-	Value *TypeSpecWithKey
+	Code        int16
+	Key         string
+	Value       *TypeSpecWithKey
+	Description string
+}
+
+// 拆分注解行, 返回注解名与参数
+func ParseAnnotation(comment string) (string, []string, bool) {
+	typ, text, ok := cutAnnotation(comment)
+	if !ok {
+		return "", nil, false
+	}
+	return typ, annotationArgs(typ, text), true
 }
 
 func (doc *DocApi) ParseComment(comment string) bool {
 
-	comment = strings.ReplaceAll(comment, "\t", " ")
-	r, _ := regexp.Compile("// *@([a-zA-Z]+) *")
+	typ, commentPieces, ok := ParseAnnotation(comment)
+	if !ok {
+		return false
+	}
+	doc.annotation = typ
+	return doc.parse(typ, commentPieces)
+}
 
-	types := r.FindSubmatch([]byte(comment))
-	if len(types) < 2 {
+func (doc *DocApi) parse(typ string, commentPieces []string) bool {
+	h, ok := annotationHandler(typ)
+	if !ok {
+		doc.report(SeverityWarning, "unknown annotation @%s", typ)
 		return false
 	}
+	return h(doc, &AnnotationContext{
+		Name: typ,
+		Pkg:  doc.pkg,
+		File: doc.file,
+		Pos:  fset.Position(doc.pos),
+		api:  doc,
+	}, commentPieces)
+}
+
+func (doc *DocApi) report(sev Severity, format string, a ...interface{}) {
+	doc.diags = append(doc.diags, newDiagnostic(sev, doc.pos, doc.file, doc.annotation, format, a...))
+}
 
-	typ := string(types[1])
-	comment = strings.ReplaceAll(comment, string(types[0]), "")
-	r, _ = regexp.Compile(" +")
-	comment = strings.Trim(r.ReplaceAllString(comment, " "), " ")
-	commentPieces := stringify.ToStringSlice(comment, " ")
-
-	switch typ {
-	case "Summary":
-		return doc.ParseSummary(commentPieces)
-	case "Desc", "Description":
-		return doc.ParseDescription(commentPieces)
-	case "Category":
-		return doc.ParseCategory(commentPieces)
-	case "Router":
-		return doc.ParseRouter(commentPieces)
-	case "Type":
-		return doc.ParseType(commentPieces)
-	case "Server":
-		return doc.ParseServer(commentPieces)
-	case "Method":
-		return doc.ParseMethod(commentPieces)
-	case "Tag", "Tags":
-		return doc.ParseTag(commentPieces)
-	case "Accept":
-		return doc.ParseAccept(commentPieces)
-	case "Header":
-		return doc.ParseHeader(commentPieces)
-	case "Rest":
-		return doc.ParseRest(commentPieces)
-	case "Body":
-		return doc.ParseBody(commentPieces)
-	case "Success":
-		return doc.ParseSuccess(commentPieces)
-	case "Fail":
-		return doc.ParseFail(commentPieces)
+// 自定义注解的内容, 导出时原样输出, OpenAPI 中为 x- 字段
+func (doc *DocApi) SetExtension(key string, value any) {
+	if doc.Extensions == nil {
+		doc.Extensions = make(map[string]any)
 	}
+	doc.Extensions[key] = value
+}
 
-	return false
+func (doc *DocApi) Diagnostics() []*Diagnostic {
+	return doc.diags
 }
 
-func (doc *DocApi) ParseSuccess(s []string) bool {
+// 注解中的类型按 Go 类型表达式解析, 如 User, *pkg.User, []User, map[string]Item 与 int64
+func (doc *DocApi) parseTypeType(typeName string) *TypeSpecWithKey {
+	expr, err := parser.ParseExpr(typeName)
+	if err != nil {
+		doc.report(SeverityError, "invalid type %s", typeName)
+		return nil
+	}
+
+	var stru *TypeSpecWithKey
+	switch e := unstar(expr).(type) {
+	case *ast.Ident, *ast.SelectorExpr:
+		if _, ok := e.(*ast.Ident); ok && isBuiltin(typeName) {
+			break
+		}
+		stru = parseTypeType(strings.TrimLeft(typeName, "*"), doc.pkg, doc.file)
+		if stru == nil {
+			doc.report(SeverityError, "unresolved type %s", typeName)
+		}
+		return stru
+	}
+
+	ts := ParseType(expr, doc.pkg, doc.file)
+	if ts == nil {
+		doc.report(SeverityError, "unsupported type %s", typeName)
+		return nil
+	}
+	// 组合类型中的类型名与单独的类型名一样, 无法解析时报告错误
+	if missing := unresolvedTypes(ts, doc.pkg, doc.file); len(missing) > 0 {
+		for _, name := range missing {
+			doc.report(SeverityError, "unresolved type %s", name)
+		}
+		return nil
+	}
+	// 表达式不在源文件中, 位置改为注解所在行
+	setPos(ts, doc.pos)
+	return &TypeSpecWithKey{TypeSpec: ts}
+}
+
+func unresolvedTypes(ts *TypeSpec, pkg *Pkg, file string) []string {
+	if ts.Type == TypeType && parseTypeType(ts.TypeName, pkg, file) == nil {
+		return []string{ts.TypeName}
+	}
+	list := []string{}
+	for _, v := range ts.Value {
+		list = append(list, unresolvedTypes(v.TypeSpec, pkg, file)...)
+	}
+	return list
+}
+
+func unstar(expr ast.Expr) ast.Expr {
+	for {
+		star, ok := expr.(*ast.StarExpr)
+		if !ok {
+			return expr
+		}
+		expr = star.X
+	}
+}
+
+func setPos(ts *TypeSpec, pos token.Pos) {
+	ts.pos = pos
+	for _, v := range ts.Value {
+		setPos(v.TypeSpec, pos)
+	}
+}
+
+// code KEY Struct, 可选 desc="描述"
+func (doc *DocApi) parseRet(s []string) *DocRet {
+	s, opts := splitOptions(s)
 	if len(s) < 3 {
-		return false
+		return nil
 	}
 
-	stru := parseTypeType(s[2], doc.pkg, doc.file)
+	stru := doc.parseTypeType(s[2])
 	if stru == nil {
-		return false
+		return nil
+	}
+
+	return &DocRet{
+		Code:        int16(stringify.ToInt(s[0])),
+		Key:         s[1],
+		Value:       stru,
+		Description: opts["desc"],
 	}
+}
 
-	docRet := &DocRet{
-		Code:  int16(stringify.ToInt(s[0])),
-		Key:   s[1],
-		Value: stru,
+func (doc *DocApi) ParseSuccess(s []string) bool {
+	docRet := doc.parseRet(s)
+	if docRet == nil {
+		return false
 	}
 
 	if doc.Success == nil {
@@ -126,21 +234,11 @@ func (doc *DocApi) ParseSuccess(s []string) bool {
 }
 
 func (doc *DocApi) ParseFail(s []string) bool {
-	if len(s) < 3 {
-		return false
-	}
-
-	stru := parseTypeType(s[2], doc.pkg, doc.file)
-	if stru == nil {
+	docRet := doc.parseRet(s)
+	if docRet == nil {
 		return false
 	}
 
-	docRet := &DocRet{
-		Code:  int16(stringify.ToInt(s[0])),
-		Key:   s[1],
-		Value: stru,
-	}
-
 	if doc.Fail == nil {
 		doc.Fail = make([]*DocRet, 0)
 	}
@@ -152,7 +250,7 @@ func (doc *DocApi) ParseBody(s []string) bool {
 	if len(s) == 0 {
 		return false
 	}
-	doc.Body = parseTypeType(s[0], doc.pkg, doc.file)
+	doc.Body = doc.parseTypeType(s[0])
 	return doc.Body != nil
 }
 
@@ -160,22 +258,31 @@ func (doc *DocApi) ParseRest(s []string) bool {
 	if len(s) == 0 {
 		return false
 	}
-	doc.Rest = parseTypeType(s[0], doc.pkg, doc.file)
+	doc.Rest = doc.parseTypeType(s[0])
 	return doc.Rest != nil
 }
 
+// KEY required 备注, 也可以写成 KEY required=true 备注
 func (doc *DocApi) ParseHeader(s []string) bool {
-	if len(s) < 3 {
+	s, opts := splitOptions(s)
+	if len(s) == 0 {
 		return false
 	}
 
-	docHeader := &DocHeader{
-		Name:   s[0],
-		Remark: s[2],
+	docHeader := &DocHeader{Name: s[0]}
+	s = s[1:]
+	if v, ok := opts["required"]; ok {
+		docHeader.Required = v == "true"
+	} else {
+		if len(s) < 2 {
+			return false
+		}
+		docHeader.Required = s[0] == "true"
+		s = s[1:]
 	}
-
-	if s[1] == "true" {
-		docHeader.Required = true
+	docHeader.Remark = strings.Join(s, " ")
+	if v, ok := opts["desc"]; ok {
+		docHeader.Remark = v
 	}
 
 	if doc.Header == nil {
@@ -186,19 +293,83 @@ func (doc *DocApi) ParseHeader(s []string) bool {
 	return true
 }
 
+var paramIn = map[string]bool{
+	"query":  true,
+	"path":   true,
+	"header": true,
+	"cookie": true,
+	"form":   true,
+}
+
+var paramRequired = map[string]bool{
+	"true":     true,
+	"required": true,
+	"false":    false,
+	"optional": false,
+}
+
+// name in type [required] 备注, path 参数总是必须的.
+// required 可以省略, 为 true/false/required/optional 以外的值时作为备注
+func (doc *DocApi) ParseParam(s []string) bool {
+	if len(s) < 3 {
+		return false
+	}
+	if !paramIn[s[1]] {
+		doc.report(SeverityError, "invalid parameter location %s, want query, path, header, cookie or form", s[1])
+		return false
+	}
+
+	value := doc.parseTypeType(s[2])
+	if value == nil {
+		return false
+	}
+
+	docParam := &DocParam{
+		Name:     s[0],
+		In:       s[1],
+		Value:    value,
+		Required: s[1] == "path",
+	}
+	s = s[3:]
+	if len(s) > 0 {
+		if required, ok := paramRequired[s[0]]; ok {
+			docParam.Required = docParam.Required || required
+			s = s[1:]
+		}
+	}
+	// 省略 required 时, 备注的第一个词占了 required 的位置, 剩余部分要拼接回去
+	docParam.Remark = strings.Join(s, " ")
+
+	if doc.Param == nil {
+		doc.Param = make([]*DocParam, 0)
+	}
+	doc.Param = append(doc.Param, docParam)
+	return true
+}
+
 func (doc *DocApi) ParseAccept(s []string) bool {
 	if len(s) == 0 {
 		return false
 	}
 
-	doc.Accept = make([]string, 0)
-	for _, s := range s {
-		doc.Accept = append(doc.Accept, stringify.ToStringSlice(s)...)
-	}
+	doc.Accept = splitList(s)
 
 	return true
 }
 
+// 参数可以用空格或逗号分隔
+func splitList(s []string) []string {
+	list := make([]string, 0)
+	for _, s := range s {
+		for _, v := range stringify.ToStringSlice(s) {
+			if v != "" {
+				list = append(list, v)
+			}
+		}
+	}
+	return list
+}
+
 func (doc *DocApi) ParseTag(s []string) bool {
 	if len(s) == 0 {
 		return false
@@ -207,10 +378,7 @@ func (doc *DocApi) ParseTag(s []string) bool {
 	if doc.Tag == nil {
 		doc.Tag = make([]string, 0)
 	}
-
-	for _, s := range s {
-		doc.Tag = append(doc.Tag, stringify.ToStringSlice(s)...)
-	}
+	doc.Tag = append(doc.Tag, splitList(s)...)
 	return true
 }
 
@@ -219,10 +387,7 @@ func (doc *DocApi) ParseMethod(s []string) bool {
 		return false
 	}
 
-	doc.Method = make([]string, 0)
-	for _, s := range s {
-		doc.Method = append(doc.Method, stringify.ToStringSlice(s)...)
-	}
+	doc.Method = splitList(s)
 	return true
 }
 
@@ -251,7 +416,7 @@ func (doc *DocApi) ParseServer(s []string) bool {
 
 func (doc *DocApi) ParseSummary(s []string) bool {
 	if len(s) > 0 {
-		doc.Summary = s[0]
+		doc.Summary = strings.Join(s, " ")
 		return true
 	}
 	return false
@@ -259,18 +424,33 @@ func (doc *DocApi) ParseSummary(s []string) bool {
 
 func (doc *DocApi) ParseDescription(s []string) bool {
 	if len(s) > 0 {
-		doc.Description = ""
-		for _, d := range s {
-			doc.Description += d
-		}
+		doc.Description = strings.Join(s, " ")
 		return true
 	}
 	return false
 }
 
+func (doc *DocApi) ParseOrder(s []string) bool {
+	if len(s) > 0 {
+		doc.Order = int(stringify.ToInt(s[0]))
+		return true
+	}
+	return false
+}
+
+func (doc *DocApi) ParseNoEnvelope(s []string) bool {
+	doc.NoEnvelope = true
+	return true
+}
+
+// 定义由包统一解析, 这里只用于识别注解
+func (doc *DocApi) parseDefine(s []string) bool {
+	return true
+}
+
 func (doc *DocApi) ParseCategory(s []string) bool {
 	if len(s) > 0 {
-		doc.Category = s[0]
+		doc.Category = strings.Join(s, " ")
 		return true
 	}
 	return false
@@ -281,16 +461,75 @@ func NewDocApi(comments *ast.CommentGroup, pkg *Pkg, file string) *DocApi {
 		return nil
 	}
 
-	doc := &DocApi{
-		Accept: []string{"json"},
-		Method: []string{"post"},
-		Type:   "http",
-		pkg:    pkg,
-		file:   file,
+	annotations := ParseAnnotations(comments)
+
+	// 中间件只由函数注释得到, 包与文件的默认值由使用中间件的接口自己带上
+	middleware := false
+	for _, a := range annotations {
+		middleware = middleware || a.Name == "Middleware"
+	}
+
+	var doc *DocApi
+	if pkg != nil && !middleware {
+		doc = pkg.defaults(file).copy()
+		doc.file = file
+	} else {
+		doc = newDefaults(pkg, file)
+	}
+
+	for _, a := range annotations {
+		// 包内的定义在解析包时处理
+		if defineAnnotations[a.Name] {
+			continue
+		}
+		doc.pos = a.Pos
+		doc.annotation = a.Name
+		n := len(doc.diags)
+		if !doc.parse(a.Name, a.Args) && len(doc.diags) == n {
+			doc.report(SeverityError, "invalid arguments: %s", a.Text)
+		}
+		doc.setPosition(a.Name)
+	}
+
+	doc.Router = withPrefix(doc.prefix, doc.Router)
+	if doc.Header != nil {
+		doc.Header = uniqueHeaders(doc.Header)
+	}
+
+	// 没有 @Summary 与 @Description 时使用函数注释
+	if doc.Router != "" && (doc.Summary == "" || doc.Description == "") {
+		summary, desc := synopsis(godocText(comments))
+		if doc.Summary == "" {
+			doc.Summary = summary
+		}
+		if doc.Description == "" {
+			doc.Description = desc
+		}
 	}
 
-	for _, v := range comments.List {
-		doc.ParseComment(v.Text)
+	doc.pos = comments.Pos()
+	doc.annotation = ""
+	if len(doc.positions) > 0 && doc.Router == "" && doc.Middleware == "" {
+		doc.annotation = "Router"
+		doc.report(SeverityError, "missing @Router on annotated function")
+		doc.annotation = ""
 	}
 	return doc
 }
+
+func (doc *DocApi) setPosition(annotation string) {
+	if doc.positions == nil {
+		doc.positions = make(map[string]token.Position)
+	}
+	if _, ok := doc.positions[annotation]; !ok {
+		doc.positions[annotation] = fset.Position(doc.pos)
+	}
+}
+
+// 注解第一次出现的位置
+func (doc *DocApi) Position(annotation string) token.Position {
+	if p, ok := doc.positions[annotation]; ok {
+		return p
+	}
+	return token.Position{Filename: doc.file}
+}
@@ -2,6 +2,8 @@ package doc
 
 import (
 	"go/ast"
+	"go/token"
+	"go/types"
 	"reflect"
 	"strings"
 
@@ -88,6 +90,8 @@ type TypeSpec struct {
 	Value    []*TypeSpecWithKey
 	pkg      *Pkg
 	file     string
+	pos      token.Pos
+	diags    []*Diagnostic
 	Doc      []string
 	Comment  string
 }
@@ -124,15 +128,19 @@ func ParseTypeSpec(t *ast.TypeSpec, pkg *Pkg, file string) *TypeSpec {
 		ts.Comment = strings.Trim(t.Comment.List[0].Text, "/ ")
 	}
 	if t.Doc != nil {
-		list := []string{}
-		for _, c := range t.Doc.List {
-			list = append(list, strings.Trim(c.Text, "/ "))
-		}
-		ts.Doc = list
+		ts.Doc = commentLines(t.Doc)
 	}
 	return ts
 }
 
+func commentLines(cg *ast.CommentGroup) []string {
+	list := []string{}
+	for _, c := range cg.List {
+		list = append(list, strings.Trim(c.Text, "/ "))
+	}
+	return list
+}
+
 func parseField(f *ast.Field, pkg *Pkg, file string) *TypeSpec {
 	ts := ParseType(f.Type, pkg, file)
 	if ts == nil {
@@ -142,11 +150,7 @@ func parseField(f *ast.Field, pkg *Pkg, file string) *TypeSpec {
 		ts.Comment = strings.Trim(f.Comment.List[0].Text, "/ ")
 	}
 	if f.Doc != nil {
-		list := []string{}
-		for _, c := range f.Doc.List {
-			list = append(list, strings.Trim(c.Text, "/ "))
-		}
-		ts.Doc = list
+		ts.Doc = commentLines(f.Doc)
 	}
 	return ts
 }
@@ -157,6 +161,7 @@ func ParseType(t ast.Expr, pkg *Pkg, file string) *TypeSpec {
 	typeSpec := &TypeSpec{
 		file: file,
 		pkg:  pkg,
+		pos:  t.Pos(),
 		Kind: reflectType.Kind(),
 	}
 
@@ -190,7 +195,7 @@ func ParseType(t ast.Expr, pkg *Pkg, file string) *TypeSpec {
 				var err error
 				t.Tags, err = structtag.Parse(strings.Trim(f.Tag.Value, "`"))
 				if err != nil {
-					return nil
+					typeSpec.diags = append(typeSpec.diags, newDiagnostic(SeverityError, f.Tag.Pos(), file, "", "invalid struct tag %s: %s", f.Tag.Value, err))
 				}
 			}
 			list = append(list, t)
@@ -252,12 +257,21 @@ func ParseType(t ast.Expr, pkg *Pkg, file string) *TypeSpec {
 
 		if ident.Obj == nil {
 			typeSpec.TypeName = ident.Name
+			if ident.Name == "any" {
+				typeSpec.Type = InterfaceType
+				return typeSpec
+			}
 			kind, ok := nameKinds[ident.Name]
-			if !ok {
+			if ok {
+				typeSpec.Kind = kind
+				typeSpec.Type = nameTypes[ident.Name]
+				return typeSpec
+			}
+			if isBuiltin(ident.Name) {
 				return nil
 			}
-			typeSpec.Kind = kind
-			typeSpec.Type = nameTypes[ident.Name]
+			// 同一个包中其它文件定义的类型, 或注解中的类型名
+			typeSpec.Type = TypeType
 			return typeSpec
 		}
 
@@ -282,6 +296,10 @@ func ParseType(t ast.Expr, pkg *Pkg, file string) *TypeSpec {
 
 }
 
+func isBuiltin(name string) bool {
+	return types.Universe.Lookup(name) != nil
+}
+
 func parseTypeType(typeName string, pkg *Pkg, file string) *TypeSpecWithKey {
 
 	paths := stringify.ToStringSlice(typeName, ".")
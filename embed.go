@@ -0,0 +1,16 @@
+package doc
+
+import (
+	_ "embed"
+	"text/template"
+)
+
+// 页面与版本号编译进程序, 运行时不依赖源码目录
+
+//go:embed index.html
+var indexHtml string
+
+//go:embed version
+var version string
+
+var indexTemplate = template.Must(template.New("index.html").Parse(indexHtml))
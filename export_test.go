@@ -0,0 +1,45 @@
+package doc
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestExport(t *testing.T) {
+	ssdoc := NewSSDoc(SSDocInfo{Title: "export"}, nil).AddPacakges("github.com/uccu/go-doc/testdata/export")
+
+	js, _ := json.Marshal(ssdoc)
+	loaded, err := Load(js)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := json.Marshal(loaded); string(again) != string(js) {
+		t.Errorf("Load output differs:\n%s\n%s", js, again)
+	}
+
+	op := loaded.OpenAPI().Paths["/user/{id}"]["put"]
+	if op == nil {
+		t.Fatal("operation missing")
+	}
+	if len(op.Parameters) != 1 || op.Parameters[0].In != "path" || op.Parameters[0].Name != "id" {
+		t.Errorf("parameters = %+v", op.Parameters)
+	}
+	body := op.RequestBody.Content["application/json"].Schema
+	if body.Properties["age"].Type != "integer" || len(body.Required) != 1 || body.Required[0] != "name" {
+		t.Errorf("request body = %+v", body)
+	}
+
+	md := loaded.Markdown()
+	for _, s := range []string{"### 修改用户", "`PUT /user/:id`", "| tags | []string |"} {
+		if !bytes.Contains(md, []byte(s)) {
+			t.Errorf("markdown missing %q", s)
+		}
+	}
+
+	code, v := loaded.Apis["default"][0].Mock()
+	mock, _ := json.Marshal(v)
+	if code != 200 || string(mock) != `{"data":{"id":1,"name":"name","tags":["string"]}}` {
+		t.Errorf("mock = %d %s", code, mock)
+	}
+}
@@ -0,0 +1,95 @@
+package doc
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+var generatedTemplate = template.Must(template.New("zz_generated_doc.go").Parse(`// Code generated by go-doc. DO NOT EDIT.
+
+package {{.}}
+
+import (
+	_ "embed"
+	"net/http"
+
+	doc "github.com/uccu/go-doc"
+)
+
+//go:embed doc.json
+var docJson []byte
+
+// 编译时生成的接口文档
+var Doc = doc.MustLoad(docJson)
+
+// 文档页面与 doc.json
+var DocHandler http.Handler = doc.Handler(Doc)
+`))
+
+// 在目录下写入 doc.json 与嵌入它的 zz_generated_doc.go, pkgName 为空时使用目录中已有的包名
+func (doc *SSDoc) Generate(dir, pkgName string) error {
+	if err := doc.Err(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	js, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "doc.json"), js, 0644); err != nil {
+		return err
+	}
+
+	if pkgName == "" {
+		pkgName = dirPkgName(dir)
+	}
+	b := &bytes.Buffer{}
+	if err := generatedTemplate.Execute(b, pkgName); err != nil {
+		return err
+	}
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "zz_generated_doc.go"), src, 0644)
+}
+
+// 目录中已有 go 文件的包名, 没有时由 GOPACKAGE 或目录名得出
+func dirPkgName(dir string) string {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") || filepath.Base(file) == "zz_generated_doc.go" {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.PackageClauseOnly)
+		if err == nil {
+			return f.Name.Name
+		}
+	}
+
+	// go generate 在指令所在目录执行, 输出到该目录时使用其包名
+	abs, _ := filepath.Abs(dir)
+	if wd, _ := os.Getwd(); wd == abs && os.Getenv("GOPACKAGE") != "" {
+		return os.Getenv("GOPACKAGE")
+	}
+	name := strings.Map(func(r rune) rune {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, strings.ToLower(filepath.Base(abs)))
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		name = "doc" + name
+	}
+	return name
+}
@@ -0,0 +1,59 @@
+package doc
+
+import (
+	"go/parser"
+	"go/token"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "apidoc")
+	ssdoc := NewSSDoc(SSDocInfo{Title: "export"}, nil).AddPacakges("github.com/uccu/go-doc/testdata/export")
+	if err := ssdoc.Generate(dir, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dir, "zz_generated_doc.go"), nil, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Name.Name != "apidoc" {
+		t.Errorf("package = %s", f.Name.Name)
+	}
+
+	js, err := os.ReadFile(filepath.Join(dir, "doc.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := MustLoad(js)
+	if loaded.Version != ssdoc.Version || len(loaded.Apis["default"]) != 1 {
+		t.Errorf("loaded = %+v", loaded)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	ssdoc := NewSSDoc(SSDocInfo{Title: "export"}, nil).AddPacakges("github.com/uccu/go-doc/testdata/export")
+	srv := httptest.NewServer(http.StripPrefix("/docs/", Handler(ssdoc)))
+	defer srv.Close()
+
+	for path, want := range map[string]string{
+		"/docs/":         "url = 'doc.json'",
+		"/docs/doc.json": `"path":"/user/:id"`,
+	} {
+		res, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		if res.StatusCode != http.StatusOK || !strings.Contains(string(b), want) {
+			t.Errorf("%s = %d, missing %q", path, res.StatusCode, want)
+		}
+	}
+}
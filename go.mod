@@ -1,8 +1,9 @@
 module github.com/uccu/go-doc
 
-go 1.14
+go 1.18
 
 require (
 	github.com/fatih/structtag v1.2.0
 	github.com/uccu/go-stringify v0.4.3
+	gopkg.in/yaml.v3 v3.0.1
 )
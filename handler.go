@@ -0,0 +1,28 @@
+package doc
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// 提供文档页面与 doc.json, 可通过 http.StripPrefix 挂载到任意路径下
+func Handler(ssdoc *SSDoc) http.Handler {
+	js, err := json.Marshal(ssdoc)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/doc.json"), r.URL.Path == "doc.json":
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(js)
+		case strings.HasSuffix(r.URL.Path, "/"), strings.HasSuffix(r.URL.Path, "/index.html"), r.URL.Path == "":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			indexTemplate.Execute(w, "doc.json")
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
@@ -0,0 +1,212 @@
+package doc
+
+import (
+	"go/ast"
+	"go/token"
+	"regexp"
+	"strings"
+)
+
+var annotationRegexp = regexp.MustCompile(`//[ \t]*@([a-zA-Z]+)[ \t]*`)
+var optionRegexp = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_-]*=`)
+var directiveRegexp = regexp.MustCompile(`^[a-z0-9]+:[a-z0-9]`)
+
+// 这些注解在前 n 个参数之后, 余下的整行作为一段文本
+var freeText = map[string]int{
+	"Summary":      0,
+	"Desc":         0,
+	"Description":  0,
+	"Category":     0,
+	"Header":       2,
+	"Param":        4,
+	"DefineHeader": 3,
+}
+
+// 一条注解, 以 \ 结尾的行与下一行合并, @Description 为空时使用函数注释
+type Annotation struct {
+	Name string
+	Args []string
+	Text string // 注解名之后的内容
+	Pos  token.Pos
+}
+
+func cutAnnotation(comment string) (string, string, bool) {
+	loc := annotationRegexp.FindStringSubmatchIndex(comment)
+	if loc == nil {
+		return "", "", false
+	}
+	return comment[loc[2]:loc[3]], strings.TrimSpace(comment[loc[1]:]), true
+}
+
+func annotationArgs(name, text string) []string {
+	n, ok := freeText[name]
+	if !ok {
+		n = -1
+	}
+	return SplitArgs(text, n)
+}
+
+// 这些注解之后不是注解的行也属于该注解, 保留换行, 作为 Markdown 文本
+var multiLine = map[string]bool{
+	"Desc":        true,
+	"Description": true,
+}
+
+// 解析注释中的所有注解
+func ParseAnnotations(comments *ast.CommentGroup) []*Annotation {
+	list := []*Annotation{}
+	if comments == nil {
+		return list
+	}
+
+	var last *Annotation
+	for _, c := range comments.List {
+		name, text, ok := cutAnnotation(c.Text)
+		switch {
+		case last != nil && strings.HasSuffix(last.Text, "\\"):
+			last.Text = strings.TrimSpace(strings.TrimSuffix(last.Text, "\\")) + " " + strings.TrimSpace(commentText(c.Text))
+		case ok:
+			last = &Annotation{Name: name, Text: text, Pos: c.Slash}
+			list = append(list, last)
+		case last != nil && multiLine[last.Name]:
+			last.Text += "\n" + commentText(c.Text)
+		}
+	}
+
+	for _, a := range list {
+		if multiLine[a.Name] {
+			a.Text = strings.TrimSpace(a.Text)
+			if a.Text == "" {
+				a.Text = godocText(comments)
+			}
+		}
+		a.Text = strings.TrimSpace(strings.TrimSuffix(a.Text, "\\"))
+		a.Args = annotationArgs(a.Name, a.Text)
+	}
+	return list
+}
+
+// 去掉注释符号与其后的一个空格, 保留缩进
+func commentText(text string) string {
+	text = strings.TrimPrefix(text, "//")
+	return strings.TrimRight(strings.TrimPrefix(text, " "), " \t")
+}
+
+// 第一个注解之前的函数注释, 不含 //go:generate 等指令
+func godocText(comments *ast.CommentGroup) string {
+	lines := []string{}
+	for _, c := range comments.List {
+		if _, _, ok := cutAnnotation(c.Text); ok {
+			break
+		}
+		if directiveRegexp.MatchString(strings.TrimPrefix(c.Text, "//")) {
+			continue
+		}
+		lines = append(lines, commentText(c.Text))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// 拆分参数, 支持 "带 空格" 的引号字符串与 key=value 选项,
+// n >= 0 时在 n 个位置参数之后余下的内容作为最后一个参数, key=value 选项不计入 n
+func SplitArgs(s string, n int) []string {
+	args := []string{}
+	positional := 0
+	for i := 0; ; {
+		for i < len(s) && isSpace(s[i]) {
+			i++
+		}
+		if i >= len(s) {
+			break
+		}
+		if n >= 0 && positional == n {
+			args = append(args, unquoteText(strings.TrimSpace(s[i:])))
+			break
+		}
+
+		var arg string
+		arg, i = lexArg(s, i)
+		if !optionRegexp.MatchString(arg) {
+			positional++
+		}
+		args = append(args, arg)
+	}
+	return args
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+// 读取一个参数, 引号内的空格不分隔参数, 引号本身被去掉
+func lexArg(s string, i int) (string, int) {
+	b := &strings.Builder{}
+	for i < len(s) && !isSpace(s[i]) {
+		q := s[i]
+		if q != '"' && q != '`' {
+			b.WriteByte(q)
+			i++
+			continue
+		}
+		for i++; i < len(s) && s[i] != q; i++ {
+			if q == '"' && s[i] == '\\' && i+1 < len(s) {
+				i++
+			}
+			b.WriteByte(s[i])
+		}
+		i++
+	}
+	return b.String(), i
+}
+
+// 整段文本被一对引号包住时去掉引号
+func unquoteText(s string) string {
+	if len(s) < 2 || s[0] != '"' && s[0] != '`' {
+		return s
+	}
+	arg, i := lexArg(s, 0)
+	if i < len(s) {
+		return s
+	}
+	return arg
+}
+
+// 分离位置参数与 key=value 选项
+func splitOptions(args []string) ([]string, map[string]string) {
+	list := []string{}
+	opts := make(map[string]string)
+	for _, a := range args {
+		if optionRegexp.MatchString(a) {
+			k, v, _ := strings.Cut(a, "=")
+			opts[k] = v
+			continue
+		}
+		list = append(list, a)
+	}
+	return list, opts
+}
+
+// 函数注释的第一句, 按 godoc 的规则以句号或空行结束
+func Synopsis(comments *ast.CommentGroup) string {
+	summary, _ := synopsis(godocText(comments))
+	return summary
+}
+
+func synopsis(text string) (string, string) {
+	text = strings.TrimSpace(text)
+	for i := 0; i < len(text); i++ {
+		end := -1
+		switch {
+		case strings.HasPrefix(text[i:], "\n\n"):
+			end = i
+		case text[i] == '.' && (i+1 == len(text) || isSpace(text[i+1]) || text[i+1] == '\n'):
+			end = i + 1
+		case strings.HasPrefix(text[i:], "。"):
+			end = i + len("。")
+		}
+		if end >= 0 {
+			return strings.ReplaceAll(text[:end], "\n", " "), strings.TrimSpace(text[end:])
+		}
+	}
+	return strings.ReplaceAll(text, "\n", " "), ""
+}
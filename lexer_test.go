@@ -0,0 +1,131 @@
+package doc
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want []string
+	}{
+		{"200 data User", -1, []string{"200", "data", "User"}},
+		{`  200	 data   User  `, -1, []string{"200", "data", "User"}},
+		{`"a b" c`, -1, []string{"a b", "c"}},
+		{`say "\"hi\""`, -1, []string{"say", `"hi"`}},
+		{"`raw \\n` x", -1, []string{`raw \n`, "x"}},
+		{`200 data User desc="返回 用户"`, -1, []string{"200", "data", "User", "desc=返回 用户"}},
+		{"Get user info", 0, []string{"Get user info"}},
+		{`"Get user info"`, 0, []string{"Get user info"}},
+		{`He said "hi" twice`, 0, []string{`He said "hi" twice`}},
+		{"Token true 访问 令牌", 2, []string{"Token", "true", "访问 令牌"}},
+		{"Token required=true 访问 令牌", 2, []string{"Token", "required=true", "访问", "令牌"}},
+		{"", 0, []string{}},
+	}
+	for _, tt := range tests {
+		if got := SplitArgs(tt.s, tt.n); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitArgs(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestParseAnnotations(t *testing.T) {
+	src := `package p
+
+// Get 获取用户
+// @Summary Get user info
+// @Description 第一行 \
+//   第二行
+// @Header Authorization true Bearer 令牌
+// @Method get,\
+// post
+func Get() {}
+`
+	f, err := parser.ParseFile(token.NewFileSet(), "p.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := ParseAnnotations(f.Decls[0].(*ast.FuncDecl).Doc)
+
+	want := []struct {
+		name string
+		args []string
+	}{
+		{"Summary", []string{"Get user info"}},
+		{"Description", []string{"第一行 第二行"}},
+		{"Header", []string{"Authorization", "true", "Bearer 令牌"}},
+		{"Method", []string{"get,", "post"}},
+	}
+	if len(list) != len(want) {
+		t.Fatalf("annotations = %d", len(list))
+	}
+	for i, w := range want {
+		if list[i].Name != w.name || !reflect.DeepEqual(list[i].Args, w.args) {
+			t.Errorf("annotations[%d] = %s %q", i, list[i].Name, list[i].Args)
+		}
+	}
+
+	api := &DocApi{}
+	for _, a := range list {
+		api.parse(a.Name, a.Args)
+	}
+	if api.Summary != "Get user info" || api.Description != "第一行 第二行" {
+		t.Errorf("api = %q %q", api.Summary, api.Description)
+	}
+	if h := api.Header[0]; !h.Required || h.Remark != "Bearer 令牌" {
+		t.Errorf("header = %+v", h)
+	}
+	if !reflect.DeepEqual(api.Method, []string{"get", "post"}) {
+		t.Errorf("method = %q", api.Method)
+	}
+}
+
+func TestMultiLineDescription(t *testing.T) {
+	src := `package p
+
+// @Summary 创建订单
+// @Description 规则:
+//
+//   - 金额 **大于** 0
+//   - 库存不足时返回 409
+//
+// ` + "```" + `
+// {"id": 1}
+// ` + "```" + `
+// @Router /order
+func Create() {}
+
+// Cancel 取消订单.
+// 只能取消未支付的订单.
+// @Summary 取消订单
+// @Description
+// @Router /order/cancel
+func Cancel() {}
+`
+	f, err := parser.ParseFile(token.NewFileSet(), "p.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"规则:\n\n  - 金额 **大于** 0\n  - 库存不足时返回 409\n\n```\n{\"id\": 1}\n```",
+		"Cancel 取消订单.\n只能取消未支付的订单.",
+	}
+	for i, w := range want {
+		api := &DocApi{}
+		for _, a := range ParseAnnotations(f.Decls[i].(*ast.FuncDecl).Doc) {
+			api.parse(a.Name, a.Args)
+		}
+		if api.Description != w {
+			t.Errorf("description = %q, want %q", api.Description, w)
+		}
+		if api.Router == "" {
+			t.Error("annotation after description lost")
+		}
+	}
+}
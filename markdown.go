@@ -0,0 +1,211 @@
+package doc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// 导出为 Markdown 文档
+func (doc *SSDoc) Markdown() []byte {
+	b := &bytes.Buffer{}
+
+	fmt.Fprintf(b, "# %s\n\n", doc.Info.Title)
+	if doc.Info.Version != "" {
+		fmt.Fprintf(b, "版本: %s\n\n", doc.Info.Version)
+	}
+	if doc.Info.Description != "" {
+		fmt.Fprintf(b, "%s\n\n", doc.Info.Description)
+	}
+
+	if len(doc.Servers) > 0 {
+		b.WriteString("| 服务 | 地址 | 描述 |\n| --- | --- | --- |\n")
+		for _, id := range sortedKeys(doc.Servers) {
+			s := doc.Servers[id]
+			fmt.Fprintf(b, "| %s | %s | %s |\n", id, s.Url, mdCell(s.Description))
+		}
+		b.WriteString("\n")
+	}
+
+	for _, category := range sortedKeys(doc.Apis) {
+		fmt.Fprintf(b, "## %s\n\n", category)
+		for _, api := range doc.Apis[category] {
+			writeMarkdownApi(b, doc, api)
+		}
+	}
+	return b.Bytes()
+}
+
+func writeMarkdownApi(b *bytes.Buffer, doc *SSDoc, api *SSDocApi) {
+	fmt.Fprintf(b, "### %s\n\n", api.Name)
+	fmt.Fprintf(b, "`%s %s`", strings.ToUpper(strings.Join(api.Method, "/")), api.Path)
+	if s, ok := doc.Servers[api.Server]; ok && api.Server != "" {
+		fmt.Fprintf(b, " (%s)", s.Url)
+	}
+	b.WriteString("\n\n")
+
+	if api.Description != "" {
+		fmt.Fprintf(b, "%s\n\n", api.Description)
+	}
+	if len(api.Tag) > 0 {
+		fmt.Fprintf(b, "标签: %s\n\n", strings.Join(api.Tag, ", "))
+	}
+
+	if len(api.Extensions) > 0 {
+		for _, k := range sortedKeys(api.Extensions) {
+			v, _ := json.Marshal(api.Extensions[k])
+			fmt.Fprintf(b, "%s: `%s`\n", k, v)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(api.Header) > 0 {
+		b.WriteString("**Header**\n\n| 名称 | 必须 | 描述 |\n| --- | --- | --- |\n")
+		for _, h := range api.Header {
+			fmt.Fprintf(b, "| %s | %s | %s |\n", h.Name, mdBool(h.Required), mdCell(h.Description))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(api.Param) > 0 {
+		b.WriteString("**Param**\n\n| 名称 | 位置 | 类型 | 必须 | 描述 |\n| --- | --- | --- | --- | --- |\n")
+		for _, p := range api.Param {
+			fmt.Fprintf(b, "| %s | %s | %s | %s | %s |\n", p.Name, p.In, markdownType(p.Value), mdBool(p.Required), mdCell(p.Description))
+		}
+		b.WriteString("\n")
+	}
+
+	if req := api.Request; req != nil {
+		writeMarkdownSection(b, "Path", req.Path, true)
+		writeMarkdownSection(b, "Query", req.Query, true)
+		writeMarkdownSection(b, "Header", req.Header, true)
+		writeMarkdownSection(b, "Form", req.Form, true)
+		writeMarkdownSection(b, "Body", req.Body, false)
+		if api.Body != nil && fieldsOf(api.Body) == nil {
+			b.WriteString("**Body**\n\n")
+			writeMarkdownFields(b, api.Body)
+		}
+	} else {
+		if api.Rest != nil {
+			b.WriteString("**Rest**\n\n")
+			writeMarkdownFields(b, api.Rest)
+		}
+		if api.Body != nil {
+			b.WriteString("**Body**\n\n")
+			writeMarkdownFields(b, api.Body)
+		}
+	}
+
+	for _, r := range api.Success {
+		writeMarkdownRet(b, "Success", r)
+	}
+	for _, r := range api.Fail {
+		writeMarkdownRet(b, "Fail", r)
+	}
+}
+
+func writeMarkdownRet(b *bytes.Buffer, title string, r *SSDocRet) {
+	fmt.Fprintf(b, "**%s %d** `%s`", title, r.Code, r.Key)
+	if r.Description != "" {
+		b.WriteString(" " + mdCell(r.Description))
+	}
+	b.WriteString("\n\n")
+	writeMarkdownFields(b, r.Value)
+}
+
+func writeMarkdownFields(b *bytes.Buffer, t *SSDocTypeWithKey) {
+	fields := fieldsOf(t)
+	if len(fields) == 0 {
+		fmt.Fprintf(b, "`%s`\n\n", markdownType(t))
+		return
+	}
+
+	b.WriteString("| 字段 | 类型 | 必须 | 默认值 | 描述 |\n| --- | --- | --- | --- | --- |\n")
+	writeMarkdownRows(b, "", fields, 0)
+	b.WriteString("\n")
+}
+
+func writeMarkdownSection(b *bytes.Buffer, title string, fields []*SSDocTypeWithKey, param bool) {
+	if len(fields) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "**%s**\n\n", title)
+	b.WriteString("| 字段 | 类型 | 必须 | 默认值 | 描述 |\n| --- | --- | --- | --- | --- |\n")
+	if param {
+		for _, f := range fields {
+			writeMarkdownRow(b, f.paramName(), f)
+			writeMarkdownRows(b, f.paramName()+".", fieldsOf(elemOf(f)), 1)
+		}
+	} else {
+		writeMarkdownRows(b, "", fields, 0)
+	}
+	b.WriteString("\n")
+}
+
+func writeMarkdownRows(b *bytes.Buffer, prefix string, fields []*SSDocTypeWithKey, depth int) {
+	for _, f := range fields {
+		name := prefix + f.name()
+		writeMarkdownRow(b, name, f)
+
+		// 限制层级, 避免自引用类型无限展开
+		if depth < 5 {
+			writeMarkdownRows(b, name+".", fieldsOf(elemOf(f)), depth+1)
+		}
+	}
+}
+
+func writeMarkdownRow(b *bytes.Buffer, name string, f *SSDocTypeWithKey) {
+	def := ""
+	if f.Default != nil {
+		def = "`" + *f.Default + "`"
+	}
+	fmt.Fprintf(b, "| %s | %s | %s | %s | %s |\n", name, markdownType(f), mdBool(f.Required), def, mdCell(f.Description))
+}
+
+// 数组与map取元素类型
+func elemOf(t *SSDocTypeWithKey) *SSDocTypeWithKey {
+	for t != nil && t.Type == TypeType && len(t.Value) > 0 {
+		t = t.Value[0]
+	}
+	if t != nil && t.Type == SliceType && len(t.Value) > 0 {
+		return elemOf(t.Value[0])
+	}
+	if t != nil && t.Type == MapType && len(t.Value) > 1 {
+		return elemOf(t.Value[1])
+	}
+	return t
+}
+
+func markdownType(t *SSDocTypeWithKey) string {
+	if t == nil || t.SSDocType == nil {
+		return ""
+	}
+	switch t.Type {
+	case SliceType:
+		if len(t.Value) > 0 {
+			return "[]" + markdownType(t.Value[0])
+		}
+	case MapType:
+		if len(t.Value) > 1 {
+			return "map[" + markdownType(t.Value[0]) + "]" + markdownType(t.Value[1])
+		}
+	case StructType:
+		if t.Name != "" {
+			return t.Name
+		}
+	}
+	return t.TypeName
+}
+
+func mdBool(b bool) string {
+	if b {
+		return "是"
+	}
+	return ""
+}
+
+func mdCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
@@ -0,0 +1,153 @@
+package doc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 中间件带来的请求头, 失败返回与标签
+type MiddlewareConf struct {
+	Headers []*SSDocHeader    `json:"headers" yaml:"headers"`
+	Fail    []*MiddlewareFail `json:"fail" yaml:"fail"`
+	Tags    []string          `json:"tags" yaml:"tags"`
+}
+
+type MiddlewareFail struct {
+	Code        int16  `json:"code" yaml:"code"`
+	Key         string `json:"key" yaml:"key"`
+	Type        string `json:"type" yaml:"type"` // 基础类型或类型的完整路径, 如 github.com/x/api.Error
+	Description string `json:"description" yaml:"description"`
+}
+
+type middleware struct {
+	header []*SSDocHeader
+	fail   []*SSDocRet
+	tag    []string
+}
+
+// 通过配置声明中间件, 同名时替换
+func (doc *SSDoc) Middleware(name string, c *MiddlewareConf) *SSDoc {
+	if c == nil {
+		return doc
+	}
+	m := &middleware{header: c.Headers, tag: c.Tags}
+	for _, f := range c.Fail {
+		value := doc.confType(f.Type)
+		if value == nil {
+			doc.report(&Diagnostic{Severity: SeverityError, Message: fmt.Sprintf("unresolved type %s of middleware %s", f.Type, name)})
+			continue
+		}
+		m.fail = append(m.fail, &SSDocRet{Code: f.Code, Key: f.Key, Value: value, Description: f.Description})
+	}
+	doc.setMiddleware(name, m)
+	return doc
+}
+
+func (doc *SSDoc) setMiddleware(name string, m *middleware) {
+	if doc.middlewares == nil {
+		doc.middlewares = make(map[string]*middleware)
+	}
+	doc.middlewares[name] = m
+}
+
+// 配置中的类型, 基础类型或类型的完整路径
+func (doc *SSDoc) confType(typeName string) *SSDocTypeWithKey {
+	if t, ok := nameTypes[typeName]; ok {
+		return &SSDocTypeWithKey{SSDocType: &SSDocType{Type: t, TypeName: typeName}}
+	}
+	ts := doc.lookupType(typeName)
+	if ts == nil {
+		return nil
+	}
+	return doc.parseType(&TypeSpecWithKey{TypeSpec: ts})
+}
+
+// 带有 @Middleware 注解的函数, 其中的 @Header, @Fail 与 @Tag 由使用它的接口继承
+func (doc *SSDoc) addMiddleware(i *DocApi) {
+	doc.report(i.diags...)
+	fail, header := doc.expandRefs(i)
+
+	m := &middleware{tag: i.Tag}
+	for _, h := range header {
+		m.header = append(m.header, &SSDocHeader{Name: h.Name, Description: h.Remark, Required: h.Required})
+	}
+	for _, r := range fail {
+		m.fail = append(m.fail, &SSDocRet{Code: r.Code, Key: r.Key, Value: doc.parseType(r.Value), Description: r.Description})
+	}
+	doc.setMiddleware(i.Middleware, m)
+}
+
+// 按 @Use 的顺序加上中间件的内容, 接口中已声明的同名请求头, 相同状态码和 KEY 的返回优先
+func (doc *SSDoc) use(i *DocApi, api *SSDocApi) {
+	for _, name := range i.Use {
+		m, ok := doc.middlewares[name]
+		if !ok {
+			doc.report(&Diagnostic{
+				Severity:   SeverityError,
+				Message:    "unknown middleware " + name,
+				Annotation: "Use",
+				Pos:        i.Position("Use"),
+			})
+			continue
+		}
+
+		for _, h := range m.header {
+			if !hasSSDocHeader(api.Header, h.Name) {
+				api.Header = append(api.Header, h)
+			}
+		}
+		for _, r := range m.fail {
+			if !hasSSDocRet(api.Fail, r) {
+				api.Fail = append(api.Fail, r)
+			}
+		}
+		for _, t := range m.tag {
+			if !contains(api.Tag, t) {
+				api.Tag = append(api.Tag[:len(api.Tag):len(api.Tag)], t)
+			}
+		}
+	}
+}
+
+func hasSSDocHeader(list []*SSDocHeader, name string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasSSDocRet(list []*SSDocRet, r *SSDocRet) bool {
+	for _, v := range list {
+		if v.Code == r.Code && v.Key == r.Key {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (doc *DocApi) ParseMiddleware(s []string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	doc.Middleware = s[0]
+	return true
+}
+
+func (doc *DocApi) ParseUse(s []string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	doc.Use = append(doc.Use, splitList(s)...)
+	return true
+}
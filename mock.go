@@ -0,0 +1,92 @@
+package doc
+
+import (
+	"strconv"
+)
+
+// 按类型生成示例数据, 有默认值时使用默认值
+func Mock(t *SSDocTypeWithKey) interface{} {
+	return mock(t, 0)
+}
+
+func mock(t *SSDocTypeWithKey, depth int) interface{} {
+	if t == nil || t.SSDocType == nil || depth > 8 {
+		return nil
+	}
+
+	def := ""
+	if t.Default != nil {
+		def = *t.Default
+	}
+
+	switch t.Type {
+	case BoolType:
+		v, err := strconv.ParseBool(def)
+		return err != nil || v
+	case IntType:
+		if v, err := strconv.ParseInt(def, 10, 64); err == nil {
+			return v
+		}
+		return 1
+	case UintType:
+		if v, err := strconv.ParseUint(def, 10, 64); err == nil {
+			return v
+		}
+		return 1
+	case FloatType:
+		if v, err := strconv.ParseFloat(def, 64); err == nil {
+			return v
+		}
+		return 1.5
+	case StringType:
+		if t.Default != nil {
+			return def
+		}
+		if n := t.name(); n != "" {
+			return n
+		}
+		return "string"
+	case StructType:
+		m := make(map[string]interface{})
+		for _, f := range fieldsOf(t) {
+			m[f.name()] = mock(f, depth+1)
+		}
+		return m
+	case SliceType:
+		if len(t.Value) > 0 {
+			return []interface{}{mock(t.Value[0], depth+1)}
+		}
+		return []interface{}{}
+	case MapType:
+		if len(t.Value) > 1 {
+			return map[string]interface{}{"key": mock(t.Value[1], depth+1)}
+		}
+		return map[string]interface{}{}
+	case TypeType:
+		if len(t.Value) > 0 {
+			v := *t.Value[0]
+			v.Key, v.Json, v.Default = t.Key, t.Json, t.Default
+			return mock(&v, depth)
+		}
+	}
+	return nil
+}
+
+// 第一个成功返回的状态码与该状态码的全部返回内容
+func (api *SSDocApi) Mock() (int, interface{}) {
+	if len(api.Success) == 0 {
+		return 200, nil
+	}
+	r := api.Success[0]
+	code := int(r.Code)
+	if code == 0 {
+		code = 200
+	}
+	v := make(map[string]interface{})
+	for _, s := range api.Success {
+		if s.Code == r.Code {
+			v[s.Key] = Mock(s.Value)
+		}
+	}
+	return code, v
+}
@@ -0,0 +1,543 @@
+package doc
+
+import (
+	"errors"
+	"go/build"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var mods *modList
+var modsOnce sync.Once
+
+type Module struct {
+	Path    string
+	Dir     string
+	Require map[string]string
+	Replace map[string]*Replace
+}
+
+type Replace struct {
+	Path    string
+	Version string
+	Dir     string
+}
+
+type modList struct {
+	main    *Module
+	mods    []*Module
+	vendor  bool
+	require map[string]string   // 主模块的依赖版本
+	replace map[string]*Replace // 主模块与 go.work 中的 replace
+	sum     map[string]string
+	cache   string
+}
+
+func setMod() {
+	modsOnce.Do(loadMods)
+}
+
+func loadMods() {
+	base, _ := os.Getwd()
+	mods = newModList(base)
+	if mods.main == nil {
+		panic(errors.New("no mod file"))
+	}
+}
+
+// 与 go 命令一致, 只有主模块与 go.work 中的 replace 生效, 工作区中 use 的模块都是主模块
+func newModList(base string) *modList {
+	l := &modList{
+		require: make(map[string]string),
+		replace: make(map[string]*Replace),
+	}
+
+	roots := []*Module{}
+	workReplace := map[string]*Replace{}
+	// 与 go 命令一样, GOWORK 可以关闭工作区或指定 go.work 文件
+	work := os.Getenv("GOWORK")
+	switch work {
+	case "off":
+		work = ""
+	case "":
+		work = findUp(base, "go.work")
+	}
+	if work != "" {
+		roots, workReplace = l.loadWork(work)
+	}
+
+	if file := findUp(base, "go.mod"); file != "" {
+		if m := parseModFile(file); m != nil {
+			l.main = l.add(m)
+			if !containsMod(roots, l.main) {
+				roots = append(roots, l.main)
+			}
+		}
+	}
+
+	if l.main == nil {
+		if len(l.mods) == 0 {
+			return l
+		}
+		l.main = l.mods[0]
+	}
+
+	for _, m := range roots {
+		// 工作区中多个模块依赖同一模块时, 与最小版本选择一样取较高的版本
+		for path, v := range m.Require {
+			if old, ok := l.require[path]; !ok || compareVersion(v, old) > 0 {
+				l.require[path] = v
+			}
+		}
+		for path, r := range m.Replace {
+			l.replace[path] = r
+		}
+	}
+	for path, r := range workReplace {
+		l.replace[path] = r
+	}
+	for path, r := range l.replace {
+		if r.Dir != "" {
+			l.add(&Module{Path: path, Dir: r.Dir})
+		}
+	}
+
+	l.sum = parseSumFile(filepath.Join(l.main.Dir, "go.sum"))
+	l.cache = modCacheDir()
+
+	// 工作区模式不使用 vendor, 主模块中的本地 replace 不影响
+	if work == "" {
+		_, err := os.Stat(filepath.Join(l.main.Dir, "vendor", "modules.txt"))
+		l.vendor = err == nil && !strings.Contains(os.Getenv("GOFLAGS"), "-mod=mod")
+	}
+
+	for _, m := range append([]*Module{}, l.mods...) {
+		l.addNested(m.Dir)
+	}
+
+	sort.SliceStable(l.mods, func(i, j int) bool {
+		return len(l.mods[i].Path) > len(l.mods[j].Path)
+	})
+	return l
+}
+
+func containsMod(list []*Module, m *Module) bool {
+	for _, v := range list {
+		if v == m {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *modList) add(m *Module) *Module {
+	for _, v := range l.mods {
+		if v.Dir == m.Dir {
+			return v
+		}
+	}
+	l.mods = append(l.mods, m)
+	return m
+}
+
+// 返回 use 的模块与 go.work 中的 replace
+func (l *modList) loadWork(file string) ([]*Module, map[string]*Replace) {
+	dir := filepath.Dir(file)
+	roots := []*Module{}
+	replace := make(map[string]*Replace)
+	for _, line := range readModLines(file) {
+		switch line[0] {
+		case "use":
+			if len(line) < 2 {
+				continue
+			}
+			m := parseModFile(filepath.Join(modPath(dir, line[1]), "go.mod"))
+			if m != nil {
+				roots = append(roots, l.add(m))
+			}
+		case "replace":
+			path, r := parseReplace(dir, line[1:])
+			if r != nil {
+				replace[path] = r
+			}
+		}
+	}
+	return roots, replace
+}
+
+func (l *modList) addNested(root string) {
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() || path == root {
+			return nil
+		}
+		name := info.Name()
+		if name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+			return filepath.SkipDir
+		}
+		if m := parseModFile(filepath.Join(path, "go.mod")); m != nil {
+			l.add(m)
+		}
+		return nil
+	})
+}
+
+// 根据导入路径找到包所在目录
+func (l *modList) dir(pkgName string) string {
+	for _, m := range l.mods {
+		if rest, ok := cutModPath(pkgName, m.Path); ok {
+			return filepath.Join(m.Dir, rest)
+		}
+	}
+
+	if l.vendor {
+		dir := filepath.Join(l.main.Dir, "vendor", pkgName)
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+	}
+
+	return l.cacheDir(pkgName)
+}
+
+// 从本地GOMODCACHE中查找依赖模块, 不访问网络
+func (l *modList) cacheDir(pkgName string) string {
+	if l.cache == "" {
+		return ""
+	}
+
+	modPath, version, rest, found := "", "", "", ""
+	for path, r := range l.replace {
+		if r.Dir != "" || len(path) <= len(found) {
+			continue
+		}
+		if s, ok := cutModPath(pkgName, path); ok {
+			found, modPath, version, rest = path, r.Path, r.Version, s
+		}
+	}
+
+	// 取最长的模块路径, 版本优先使用 go.mod 中 require 的, 没有时使用 go.sum 中最高的
+	if modPath == "" {
+		for _, list := range []map[string]string{l.require, l.sum} {
+			for path := range list {
+				if len(path) <= len(found) {
+					continue
+				}
+				if s, ok := cutModPath(pkgName, path); ok {
+					found, rest = path, s
+				}
+			}
+		}
+		modPath = found
+		version = l.require[found]
+		if version == "" {
+			version = l.sum[found]
+		}
+	}
+
+	if modPath == "" || version == "" {
+		return ""
+	}
+
+	dir := filepath.Join(l.cache, escapeModPath(modPath)+"@"+escapeModPath(version), rest)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return ""
+	}
+	return dir
+}
+
+func pkgDir(pkgName string) string {
+	setMod()
+	return mods.dir(pkgName)
+}
+
+func modCacheDir() string {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return dir
+	}
+	gopath := filepath.SplitList(build.Default.GOPATH)
+	if len(gopath) == 0 || gopath[0] == "" {
+		return ""
+	}
+	return filepath.Join(gopath[0], "pkg", "mod")
+}
+
+// 大写字母转义为 !+小写, 与模块缓存目录一致
+func escapeModPath(s string) string {
+	b := strings.Builder{}
+	for _, r := range s {
+		if r >= 'A' && r <= 'Z' {
+			b.WriteByte('!')
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func cutModPath(pkgName, modPath string) (string, bool) {
+	if pkgName == modPath {
+		return "", true
+	}
+	if strings.HasPrefix(pkgName, modPath+"/") {
+		return pkgName[len(modPath)+1:], true
+	}
+	return "", false
+}
+
+func findUp(dir, name string) string {
+	for {
+		file := filepath.Join(dir, name)
+		if _, err := os.Stat(file); err == nil {
+			return file
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+func parseModFile(file string) *Module {
+	lines := readModLines(file)
+	if lines == nil {
+		return nil
+	}
+
+	dir := filepath.Dir(file)
+	m := &Module{
+		Dir:     dir,
+		Require: make(map[string]string),
+		Replace: make(map[string]*Replace),
+	}
+
+	for _, line := range lines {
+		switch line[0] {
+		case "module":
+			if len(line) > 1 {
+				m.Path = line[1]
+			}
+		case "require":
+			if len(line) > 2 {
+				m.Require[line[1]] = line[2]
+			}
+		case "replace":
+			path, r := parseReplace(dir, line[1:])
+			if r != nil {
+				m.Replace[path] = r
+			}
+		}
+	}
+
+	if m.Path == "" {
+		return nil
+	}
+	return m
+}
+
+// old [v] => new [v]
+func parseReplace(dir string, s []string) (string, *Replace) {
+	i := 0
+	for i < len(s) && s[i] != "=>" {
+		i++
+	}
+	if i == 0 || i+1 >= len(s) {
+		return "", nil
+	}
+
+	r := &Replace{Path: s[i+1]}
+	if i+2 < len(s) {
+		r.Version = s[i+2]
+	} else if isLocalPath(r.Path) {
+		r.Dir = modPath(dir, r.Path)
+	}
+	return s[0], r
+}
+
+func isLocalPath(p string) bool {
+	return strings.HasPrefix(p, "./") || strings.HasPrefix(p, "../") || p == "." || p == ".." || filepath.IsAbs(p)
+}
+
+func modPath(dir, p string) string {
+	if filepath.IsAbs(p) {
+		return filepath.Clean(p)
+	}
+	return filepath.Join(dir, p)
+}
+
+// go.sum 中每个模块取最高的版本
+func parseSumFile(file string) map[string]string {
+	sum := make(map[string]string)
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return sum
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasSuffix(fields[1], "/go.mod") {
+			continue
+		}
+		if old, ok := sum[fields[0]]; !ok || compareVersion(fields[1], old) > 0 {
+			sum[fields[0]] = fields[1]
+		}
+	}
+	return sum
+}
+
+// 按语义化版本比较, 返回 -1, 0 或 1, 忽略 +incompatible 等构建信息
+func compareVersion(a, b string) int {
+	na, pa := splitVersion(a)
+	nb, pb := splitVersion(b)
+	for i := 0; i < 3; i++ {
+		if c := compareNum(na[i], nb[i]); c != 0 {
+			return c
+		}
+	}
+
+	// 没有预发布标识的版本更高
+	switch {
+	case pa == pb:
+		return 0
+	case pa == "":
+		return 1
+	case pb == "":
+		return -1
+	}
+
+	x, y := strings.Split(pa, "."), strings.Split(pb, ".")
+	for i := 0; i < len(x) && i < len(y); i++ {
+		if x[i] == y[i] {
+			continue
+		}
+		xn, yn := isNum(x[i]), isNum(y[i])
+		switch {
+		case xn && yn:
+			return compareNum(x[i], y[i])
+		case xn:
+			return -1
+		case yn:
+			return 1
+		case x[i] < y[i]:
+			return -1
+		default:
+			return 1
+		}
+	}
+	// 前面的标识都相同时, 标识多的版本更高
+	if len(x) < len(y) {
+		return -1
+	}
+	return 1
+}
+
+// v1.2.3-pre+build 拆分为 [1 2 3] 与 pre
+func splitVersion(v string) ([3]string, string) {
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.Index(v, "+"); i >= 0 {
+		v = v[:i]
+	}
+	pre := ""
+	if i := strings.Index(v, "-"); i >= 0 {
+		v, pre = v[:i], v[i+1:]
+	}
+	nums := [3]string{"0", "0", "0"}
+	for i, n := range strings.SplitN(v, ".", 3) {
+		nums[i] = n
+	}
+	return nums, pre
+}
+
+func isNum(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// 比较十进制数字串, 先比较长度, 避免 9 大于 10
+func compareNum(a, b string) int {
+	a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+	switch {
+	case len(a) != len(b):
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// 读取go.mod/go.work, 展开块语法, 每行返回 [指令, 参数...]
+func readModLines(file string) [][]string {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+
+	lines := [][]string{}
+	block := ""
+	for _, line := range strings.Split(string(b), "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		for i, f := range fields {
+			fields[i] = strings.Trim(f, "\"`")
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		if block != "" {
+			if fields[0] == ")" {
+				block = ""
+				continue
+			}
+			lines = append(lines, append([]string{block}, fields...))
+			continue
+		}
+
+		if len(fields) == 2 && fields[1] == "(" {
+			block = fields[0]
+			continue
+		}
+		lines = append(lines, fields)
+	}
+	return lines
+}
+
+// 根据目录找到对应的导入路径
+func pkgPath(dir string) string {
+	setMod()
+	dir, _ = filepath.Abs(dir)
+	found := (*Module)(nil)
+	for _, m := range mods.mods {
+		if rel, err := filepath.Rel(m.Dir, dir); err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		if found == nil || len(m.Dir) > len(found.Dir) {
+			found = m
+		}
+	}
+	if found == nil {
+		return ""
+	}
+	rel, _ := filepath.Rel(found.Dir, dir)
+	if rel == "." {
+		return found.Path
+	}
+	return found.Path + "/" + filepath.ToSlash(rel)
+}
@@ -0,0 +1,141 @@
+package doc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseModFile(t *testing.T) {
+	m := parseModFile("testdata/mod/go.mod")
+	if m == nil {
+		t.Fatal("go.mod not parsed")
+	}
+	if m.Path != "example.com/app" {
+		t.Errorf("module path = %q", m.Path)
+	}
+	if m.Require["github.com/shopspring/decimal"] != "v1.3.1" || m.Require["example.com/models"] != "v1.2.0" {
+		t.Errorf("require = %v", m.Require)
+	}
+
+	r := m.Replace["example.com/models"]
+	if r == nil || r.Dir != filepath.Join("testdata", "models") {
+		t.Errorf("local replace = %+v", r)
+	}
+	r = m.Replace["example.com/lib"]
+	if r == nil || r.Path != "example.com/fork/lib" || r.Version != "v1.0.1" || r.Dir != "" {
+		t.Errorf("module replace = %+v", r)
+	}
+}
+
+func TestPkgDir(t *testing.T) {
+	dir := pkgDir("github.com/uccu/go-doc/testdata/mod")
+	if filepath.Base(dir) != "mod" {
+		t.Errorf("dir = %q", dir)
+	}
+	if pkgDir("fmt") != "" {
+		t.Error("std package resolved inside module")
+	}
+}
+
+func TestModCacheDir(t *testing.T) {
+	if escapeModPath("github.com/BurntSushi/toml") != "github.com/!burnt!sushi/toml" {
+		t.Error(escapeModPath("github.com/BurntSushi/toml"))
+	}
+
+	dir := pkgDir("github.com/fatih/structtag")
+	if dir == "" {
+		t.Skip("github.com/fatih/structtag not in module cache")
+	}
+	pkg := GetPkg("github.com/fatih/structtag")
+	if pkg == nil || pkg.GetStru("Tag") == nil {
+		t.Error("types of a dependency module not resolved")
+	}
+}
+
+func TestModResolve(t *testing.T) {
+	t.Setenv("GOFLAGS", "")
+	abs := func(p string) string {
+		p, _ = filepath.Abs(filepath.FromSlash(p))
+		return p
+	}
+
+	// 不受仓库根目录 go.work 的影响, 工作区的用例指定 go.work
+	work := abs("testdata/modres/work/go.work")
+	tests := []struct {
+		base, pkgName, dir string
+		work               string
+	}{
+		{"testdata/modres/replace/app", "example.com/models/user", "testdata/modres/replace/models/user", "off"},
+		// 被替换的模块中的 replace 不生效
+		{"testdata/modres/replace/app", "example.com/nested", "", "off"},
+		{"testdata/modres/work/a", "example.com/b/api", "testdata/modres/work/b/api", work},
+		{"testdata/modres/work/a", "example.com/c", "testdata/modres/work/c", work},
+		{"testdata/modres/vendor", "example.com/dep", "testdata/modres/vendor/vendor/example.com/dep", "off"},
+		// 有本地 replace 时仍然使用 vendor
+		{"testdata/modres/vendorreplace/app", "example.com/dep", "testdata/modres/vendorreplace/app/vendor/example.com/dep", "off"},
+		{"testdata/modres/vendorreplace/app", "example.com/lib", "testdata/modres/vendorreplace/lib", "off"},
+	}
+	for _, tt := range tests {
+		want := ""
+		if tt.dir != "" {
+			want = abs(tt.dir)
+		}
+		t.Setenv("GOWORK", tt.work)
+		if dir := newModList(abs(tt.base)).dir(tt.pkgName); dir != want {
+			t.Errorf("%s from %s = %q, want %q", tt.pkgName, tt.base, dir, want)
+		}
+	}
+
+	t.Setenv("GOWORK", "off")
+	if dir := newModList(abs("testdata/modres/work/a")).dir("example.com/b/api"); dir != "" {
+		t.Errorf("workspace used with GOWORK=off: %q", dir)
+	}
+}
+
+func TestCompareVersion(t *testing.T) {
+	ordered := []string{
+		"v0.0.0-20200101000000-abcdef123456",
+		"v0.1.0",
+		"v1.2.3-alpha",
+		"v1.2.3-alpha.1",
+		"v1.2.3-alpha.beta",
+		"v1.2.3-beta.2",
+		"v1.2.3-beta.11",
+		"v1.2.3",
+		"v1.9.0",
+		"v1.10.0",
+		"v2.0.0+incompatible",
+	}
+	for i := 1; i < len(ordered); i++ {
+		if compareVersion(ordered[i-1], ordered[i]) != -1 || compareVersion(ordered[i], ordered[i-1]) != 1 {
+			t.Errorf("%s should be lower than %s", ordered[i-1], ordered[i])
+		}
+	}
+	if compareVersion("v1.2.3", "v1.2.3+meta") != 0 {
+		t.Error("build metadata should be ignored")
+	}
+}
+
+func TestModVersion(t *testing.T) {
+	cache := t.TempDir()
+	for _, v := range []string{"example.com/lib@v1.9.0", "example.com/lib@v1.10.0", "example.com/lib@v1.11.0", "example.com/other@v1.10.0"} {
+		os.MkdirAll(filepath.Join(cache, v), os.ModePerm)
+	}
+
+	sum := filepath.Join(t.TempDir(), "go.sum")
+	os.WriteFile(sum, []byte("example.com/other v1.10.0 h1:x\nexample.com/other v1.9.0 h1:x\nexample.com/lib v1.11.0 h1:x\n"), 0644)
+
+	l := &modList{
+		cache:   cache,
+		require: map[string]string{"example.com/lib": "v1.10.0"},
+		replace: map[string]*Replace{},
+		sum:     parseSumFile(sum),
+	}
+	if dir := l.cacheDir("example.com/lib"); filepath.Base(dir) != "lib@v1.10.0" {
+		t.Errorf("required version not used: %q", dir)
+	}
+	if dir := l.cacheDir("example.com/other"); filepath.Base(dir) != "other@v1.10.0" {
+		t.Errorf("highest go.sum version not used: %q", dir)
+	}
+}
@@ -0,0 +1,442 @@
+package doc
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type OpenAPI struct {
+	Openapi string                                  `json:"openapi"`
+	Info    OpenAPIInfo                             `json:"info"`
+	Servers []*OpenAPIServer                        `json:"servers,omitempty"`
+	Paths   map[string]map[string]*OpenAPIOperation `json:"paths"`
+}
+
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type OpenAPIServer struct {
+	Url         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+type OpenAPIOperation struct {
+	Summary     string                      `json:"summary,omitempty"`
+	Description string                      `json:"description,omitempty"`
+	OperationId string                      `json:"operationId,omitempty"`
+	Tags        []string                    `json:"tags,omitempty"`
+	Servers     []*OpenAPIServer            `json:"servers,omitempty"`
+	Parameters  []*OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses"`
+	Extensions  map[string]any              `json:"-"` // 输出为 x- 字段
+}
+
+func (op *OpenAPIOperation) MarshalJSON() ([]byte, error) {
+	type operation OpenAPIOperation
+	b, err := json.Marshal((*operation)(op))
+	if err != nil || len(op.Extensions) == 0 {
+		return b, err
+	}
+
+	m := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	for k, v := range op.Extensions {
+		if !strings.HasPrefix(k, "x-") {
+			k = "x-" + k
+		}
+		if m[k], err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(m)
+}
+
+type OpenAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *OpenAPISchema `json:"schema,omitempty"`
+}
+
+type OpenAPIRequestBody struct {
+	Required bool                         `json:"required,omitempty"`
+	Content  map[string]*OpenAPIMediaType `json:"content"`
+}
+
+type OpenAPIResponse struct {
+	Description string                       `json:"description"`
+	Content     map[string]*OpenAPIMediaType `json:"content,omitempty"`
+}
+
+type OpenAPIMediaType struct {
+	Schema *OpenAPISchema `json:"schema,omitempty"`
+}
+
+type OpenAPISchema struct {
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Title                string                    `json:"title,omitempty"`
+	Description          string                    `json:"description,omitempty"`
+	Default              *string                   `json:"default,omitempty"`
+	Minimum              *int                      `json:"minimum,omitempty"`
+	Properties           map[string]*OpenAPISchema `json:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	Items                *OpenAPISchema            `json:"items,omitempty"`
+	AdditionalProperties *OpenAPISchema            `json:"additionalProperties,omitempty"`
+}
+
+var mediaTypes = map[string]string{
+	"json":      "application/json",
+	"xml":       "application/xml",
+	"form":      "application/x-www-form-urlencoded",
+	"multipart": "multipart/form-data",
+	"text":      "text/plain",
+}
+
+func mediaType(accept string) string {
+	if t, ok := mediaTypes[accept]; ok {
+		return t
+	}
+	return accept
+}
+
+// 转换为 OpenAPI 3.0 文档, 只包含 http 接口
+func (doc *SSDoc) OpenAPI() *OpenAPI {
+	o := &OpenAPI{
+		Openapi: "3.0.3",
+		Info: OpenAPIInfo{
+			Title:       doc.Info.Title,
+			Description: doc.Info.Description,
+			Version:     doc.Info.Version,
+		},
+		Paths: make(map[string]map[string]*OpenAPIOperation),
+	}
+
+	for _, id := range sortedKeys(doc.Servers) {
+		s := doc.Servers[id]
+		o.Servers = append(o.Servers, &OpenAPIServer{Url: s.Url, Description: s.Description})
+	}
+
+	for _, category := range sortedKeys(doc.Apis) {
+		for _, api := range doc.Apis[category] {
+			if api.Type != "" && api.Type != "http" {
+				continue
+			}
+			path := api.Template
+			if path == "" {
+				path = PathTemplate(api.Path)
+			}
+			if o.Paths[path] == nil {
+				o.Paths[path] = make(map[string]*OpenAPIOperation)
+			}
+			for _, m := range api.Method {
+				o.Paths[path][strings.ToLower(m)] = doc.openAPIOperation(api, m)
+			}
+		}
+	}
+	return o
+}
+
+func (doc *SSDoc) openAPIOperation(api *SSDocApi, method string) *OpenAPIOperation {
+	op := &OpenAPIOperation{
+		Summary:     api.Name,
+		Description: api.Description,
+		Tags:        append([]string{string(api.Category)}, api.Tag...),
+		Responses:   make(map[string]*OpenAPIResponse),
+		Extensions:  api.Extensions,
+	}
+	if len(api.Method) > 1 {
+		op.OperationId = strings.ToLower(method) + " " + api.Path
+	}
+
+	if s, ok := doc.Servers[api.Server]; ok && api.Server != "" {
+		op.Servers = []*OpenAPIServer{{Url: s.Url, Description: s.Description}}
+	}
+
+	for _, h := range api.Header {
+		op.Parameters = append(op.Parameters, &OpenAPIParameter{
+			Name:        h.Name,
+			In:          "header",
+			Description: h.Description,
+			Required:    h.Required,
+			Schema:      &OpenAPISchema{Type: "string"},
+		})
+	}
+
+	form := []*SSDocParam{}
+	for _, p := range requestParams(api) {
+		if p.In == "form" {
+			form = append(form, p)
+			continue
+		}
+		op.Parameters = append(op.Parameters, &OpenAPIParameter{
+			Name:        p.Name,
+			In:          p.In,
+			Description: p.Description,
+			Required:    p.Required || p.In == "path",
+			Schema:      openAPISchema(p.Value),
+		})
+	}
+
+	if api.Body != nil {
+		schema := openAPISchema(api.Body)
+		// 只保留请求体中的字段
+		if api.Request != nil && schema.Properties != nil {
+			schema.Properties = make(map[string]*OpenAPISchema)
+			schema.Required = nil
+			for _, f := range api.Request.Body {
+				schema.Properties[f.name()] = openAPISchema(f)
+				if f.Required {
+					schema.Required = append(schema.Required, f.name())
+				}
+			}
+		}
+		if schema.Properties == nil || len(schema.Properties) > 0 {
+			op.RequestBody = &OpenAPIRequestBody{
+				Required: true,
+				Content:  make(map[string]*OpenAPIMediaType),
+			}
+			for _, a := range acceptOf(api) {
+				op.RequestBody.Content[mediaType(a)] = &OpenAPIMediaType{Schema: schema}
+			}
+		}
+	}
+
+	// form 参数合并为表单请求体
+	if len(form) > 0 {
+		if op.RequestBody == nil {
+			op.RequestBody = &OpenAPIRequestBody{
+				Required: true,
+				Content:  make(map[string]*OpenAPIMediaType),
+			}
+		}
+		m, ok := op.RequestBody.Content[mediaType("form")]
+		if !ok || m.Schema == nil || m.Schema.Properties == nil {
+			m = &OpenAPIMediaType{Schema: &OpenAPISchema{Type: "object", Properties: make(map[string]*OpenAPISchema)}}
+			op.RequestBody.Content[mediaType("form")] = m
+		}
+		for _, p := range form {
+			schema := openAPISchema(p.Value)
+			if p.Description != "" {
+				schema.Description = p.Description
+			}
+			m.Schema.Properties[p.Name] = schema
+			if p.Required {
+				m.Schema.Required = append(m.Schema.Required, p.Name)
+			}
+		}
+	}
+
+	for _, list := range [][]*SSDocRet{api.Success, api.Fail} {
+		for _, r := range list {
+			code := strconv.Itoa(int(r.Code))
+			res, ok := op.Responses[code]
+			if !ok {
+				res = &OpenAPIResponse{Description: code, Content: make(map[string]*OpenAPIMediaType)}
+				op.Responses[code] = res
+			}
+			if r.Description != "" && res.Description == code {
+				res.Description = r.Description
+			}
+			for _, a := range acceptOf(api) {
+				m, ok := res.Content[mediaType(a)]
+				if !ok {
+					m = &OpenAPIMediaType{Schema: &OpenAPISchema{Type: "object", Properties: make(map[string]*OpenAPISchema)}}
+					res.Content[mediaType(a)] = m
+				}
+				m.Schema.Properties[r.Key] = openAPISchema(r.Value)
+			}
+		}
+	}
+	if len(op.Responses) == 0 {
+		op.Responses["default"] = &OpenAPIResponse{Description: "default"}
+	}
+	return op
+}
+
+// 请求结构体中的路径, 查询, 表单参数与请求头, 以及 @Param 声明的参数
+func requestParams(api *SSDocApi) []*SSDocParam {
+	params := []*SSDocParam{}
+	add := func(in string, list []*SSDocTypeWithKey) {
+		for _, f := range list {
+			params = append(params, &SSDocParam{
+				Name:        f.paramName(),
+				In:          in,
+				Description: f.Description,
+				Required:    f.Required,
+				Value:       f,
+			})
+		}
+	}
+
+	if req := api.Request; req != nil {
+		add("path", req.Path)
+		add("query", req.Query)
+		add("header", req.Header)
+		add("form", req.Form)
+	} else if api.Rest != nil {
+		path := make(map[string]bool)
+		for _, p := range PathParams(api.Path) {
+			path[p] = true
+		}
+		for _, f := range fieldsOf(api.Rest) {
+			if path[f.name()] {
+				add("path", []*SSDocTypeWithKey{f})
+			} else {
+				add("query", []*SSDocTypeWithKey{f})
+			}
+		}
+	}
+	params = append(params, api.Param...)
+
+	// OpenAPI 要求路径模板中的参数都有声明, 且名字与模板一致
+	for _, r := range routeParams(api.Path) {
+		found := false
+		for j, p := range params {
+			if p.In == "path" && strings.EqualFold(p.Name, r.name) {
+				if p.Name != r.name {
+					c := *p
+					c.Name = r.name
+					params[j] = &c
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			params = append(params, &SSDocParam{
+				Name:     r.name,
+				In:       "path",
+				Required: true,
+				Value:    &SSDocTypeWithKey{SSDocType: &SSDocType{Type: StringType, TypeName: "string"}},
+			})
+		}
+	}
+	return params
+}
+
+func acceptOf(api *SSDocApi) []string {
+	if len(api.Accept) == 0 {
+		return []string{DefaultAccept}
+	}
+	return api.Accept
+}
+
+func openAPISchema(t *SSDocTypeWithKey) *OpenAPISchema {
+	if t == nil || t.SSDocType == nil {
+		return &OpenAPISchema{}
+	}
+
+	s := &OpenAPISchema{Description: t.Description, Default: t.Default}
+	switch t.Type {
+	case BoolType:
+		s.Type = "boolean"
+	case IntType:
+		s.Type = "integer"
+		if strings.HasSuffix(t.TypeName, "64") {
+			s.Format = "int64"
+		}
+	case UintType:
+		s.Type = "integer"
+		zero := 0
+		s.Minimum = &zero
+	case FloatType:
+		s.Type = "number"
+		if t.TypeName == "float32" {
+			s.Format = "float"
+		}
+	case StringType:
+		s.Type = "string"
+	case StructType:
+		s.Type = "object"
+		s.Title = t.Name
+		s.Properties = make(map[string]*OpenAPISchema)
+		for _, f := range fieldsOf(t) {
+			s.Properties[f.name()] = openAPISchema(f)
+			if f.Required {
+				s.Required = append(s.Required, f.name())
+			}
+		}
+	case SliceType:
+		s.Type = "array"
+		if len(t.Value) > 0 {
+			s.Items = openAPISchema(t.Value[0])
+		}
+	case MapType:
+		s.Type = "object"
+		if len(t.Value) > 1 {
+			s.AdditionalProperties = openAPISchema(t.Value[1])
+		}
+	case TypeType:
+		if len(t.Value) > 0 {
+			v := openAPISchema(t.Value[0])
+			if s.Description != "" {
+				v.Description = s.Description
+			}
+			if s.Default != nil {
+				v.Default = s.Default
+			}
+			return v
+		}
+	case CustomType:
+		if s.Description == "" {
+			s.Description = t.TypeName
+		}
+	}
+	return s
+}
+
+// 结构体字段, 匿名嵌入的结构体字段展开到外层
+func fieldsOf(t *SSDocTypeWithKey) []*SSDocTypeWithKey {
+	for t != nil && t.Type == TypeType && len(t.Value) > 0 {
+		t = t.Value[0]
+	}
+	if t == nil || t.Type != StructType {
+		return nil
+	}
+
+	list := []*SSDocTypeWithKey{}
+	for _, f := range t.Value {
+		if f.Key == "" && f.Json == nil {
+			list = append(list, fieldsOf(f)...)
+			continue
+		}
+		list = append(list, f)
+	}
+	return list
+}
+
+func (t *SSDocTypeWithKey) name() string {
+	if t.Json != nil && *t.Json != "" {
+		return *t.Json
+	}
+	return t.Key
+}
+
+// 作为路径, 查询, 表单参数或请求头时的名字
+func (t *SSDocTypeWithKey) paramName() string {
+	if t.Param != nil {
+		return *t.Param
+	}
+	return t.name()
+}
+
+func sortedKeys[K ~string, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i] < keys[j]
+	})
+	return keys
+}
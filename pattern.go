@@ -0,0 +1,117 @@
+package doc
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// 展开包路径, 支持 ./... 与 module/api/... 形式
+// 通配符只保留含有 @Router 或 @Middleware 注释的包, exclude 为导入路径的 glob
+func ExpandPackages(patterns []string, exclude []string) []string {
+	return defaultLoader().expandPackages(patterns, exclude)
+}
+
+func (l *loader) expandPackages(patterns []string, exclude []string) []string {
+	list := []string{}
+	seen := make(map[string]bool)
+	add := func(p string) {
+		if p == "" || seen[p] || excluded(p, exclude) {
+			return
+		}
+		seen[p] = true
+		list = append(list, p)
+	}
+
+	for _, p := range patterns {
+		wildcard := p == "..." || strings.HasSuffix(p, "/...")
+		base := strings.TrimSuffix(strings.TrimSuffix(p, "..."), "/")
+
+		if isLocalPath(p) {
+			base = pkgPath(base)
+		}
+
+		if !wildcard {
+			add(base)
+			continue
+		}
+
+		dir := pkgDir(base)
+		if dir == "" {
+			continue
+		}
+		for _, p := range l.walkPackages(base, dir) {
+			add(p)
+		}
+	}
+	return list
+}
+
+func (l *loader) walkPackages(pkgName, root string) []string {
+	list := []string{}
+	filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return nil
+		}
+		if p != root {
+			name := info.Name()
+			if name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(p, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+		}
+		if !l.hasAnnotations(p) {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, p)
+		if rel == "." {
+			list = append(list, pkgName)
+		} else {
+			list = append(list, path.Join(pkgName, filepath.ToSlash(rel)))
+		}
+		return nil
+	})
+	return list
+}
+
+// 只有中间件的包也要加载, 其它包中的接口可以 @Use 这些中间件
+func (l *loader) hasAnnotations(dir string) bool {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	match := l.matchFile(dir)
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".go") || !match(f) {
+			continue
+		}
+		b, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err == nil && (bytes.Contains(b, []byte("@Router")) || bytes.Contains(b, []byte("@Middleware"))) {
+			return true
+		}
+	}
+	return false
+}
+
+func excluded(pkgName string, exclude []string) bool {
+	for _, e := range exclude {
+		if !strings.HasSuffix(e, "/...") {
+			if ok, _ := path.Match(e, pkgName); ok {
+				return true
+			}
+			continue
+		}
+		e = strings.TrimSuffix(e, "/...")
+		for p := pkgName; p != "." && p != "/"; p = path.Dir(p) {
+			if ok, _ := path.Match(e, p); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -1,108 +1,288 @@
 package doc
 
 import (
-	"bufio"
-	"errors"
 	"go/ast"
+	"go/build"
 	"go/parser"
 	"go/token"
 	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/uccu/go-stringify"
 )
 
-var pkgs map[string]*Pkg
-var pkgMod string
+// 所有包共用一个 FileSet, 以便之后定位注释所在的文件与行
+var fset = token.NewFileSet()
 
-func init() {
-	pkgs = make(map[string]*Pkg)
+// 不指定编译条件时使用的 loader
+var std = newLoader(build.Default, runtime.NumCPU())
+var stdLock sync.Mutex
+
+type pkgEntry struct {
+	once sync.Once
+	pkg  *Pkg
 }
 
-type Pkg struct {
-	Dir  string
-	Name string
-	pkg  *ast.Package
-	pkgs map[string]map[string]*Pkg
-	stru map[string]*TypeSpec
-}
-
-func setMod() {
-	if pkgMod == "" {
-		base, _ := os.Getwd()
-		f, err := os.Open(base + "/go.mod")
-		if err != nil {
-			panic(err)
-		}
-		rd := bufio.NewScanner(f)
-		if rd.Scan() {
-			pkgMod = string(([]byte(rd.Text()))[7:])
-		} else {
-			panic(errors.New("no mod file"))
+// 按同一编译条件解析包, 不同编译条件的 loader 互不影响
+type loader struct {
+	ctx     build.Context
+	pkgs    map[string]*pkgEntry
+	lock    sync.Mutex
+	workers chan struct{} // 同时解析目录的数量
+	parsed  []*Pkg        // 新解析, 还未写入缓存的包
+}
+
+func newLoader(ctx build.Context, workers int) *loader {
+	if workers < 1 {
+		workers = 1
+	}
+	ctx.BuildTags = append([]string{}, ctx.BuildTags...)
+	return &loader{
+		ctx:     ctx,
+		pkgs:    make(map[string]*pkgEntry),
+		workers: make(chan struct{}, workers),
+	}
+}
+
+func defaultLoader() *loader {
+	stdLock.Lock()
+	defer stdLock.Unlock()
+	return std
+}
+
+// 只解析在指定 tag 下会被编译的文件
+func SetBuildTags(tags ...string) {
+	stdLock.Lock()
+	defer stdLock.Unlock()
+	std = std.withTags(tags)
+}
+
+func SetPlatform(goos, goarch string) {
+	stdLock.Lock()
+	defer stdLock.Unlock()
+	std = std.withPlatform(goos, goarch)
+}
+
+func SetWorkers(n int) {
+	defaultLoader().setWorkers(n)
+}
+
+func (l *loader) withTags(tags []string) *loader {
+	ctx := l.ctx
+	ctx.BuildTags = tags
+	return newLoader(ctx, cap(l.pool()))
+}
+
+func (l *loader) withPlatform(goos, goarch string) *loader {
+	ctx := l.ctx
+	if goos != "" {
+		ctx.GOOS = goos
+	}
+	if goarch != "" {
+		ctx.GOARCH = goarch
+	}
+	return newLoader(ctx, cap(l.pool()))
+}
+
+func (l *loader) setWorkers(n int) {
+	if n < 1 {
+		n = 1
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.workers = make(chan struct{}, n)
+}
+
+func (l *loader) pool() chan struct{} {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.workers
+}
+
+func (l *loader) matchFile(dir string) func(os.FileInfo) bool {
+	return func(fi os.FileInfo) bool {
+		if strings.HasSuffix(fi.Name(), "_test.go") {
+			return false
 		}
-		f.Close()
+		ok, err := l.ctx.MatchFile(dir, fi.Name())
+		return ok && err == nil
 	}
 }
 
+type Pkg struct {
+	Dir          string
+	Path         string
+	Name         string
+	loader       *loader
+	pkg          *ast.Package
+	cache        *pkgCache
+	key          string
+	pkgs         map[string]map[string]*Pkg
+	imports      map[string]map[string]string // 每个文件中包名对应的导入路径, 包括无法解析的包
+	stru         map[string]*TypeSpec
+	pkgsOnce     sync.Once
+	struOnce     sync.Once
+	defines      *DocDefines
+	definesOnce  sync.Once
+	pkgDefaults  *DocApi
+	fileDefaults map[string]*DocApi
+	defaultsOnce sync.Once
+}
+
 func GetPkg(pkgName string) *Pkg {
+	return defaultLoader().get(pkgName)
+}
 
-	setMod()
+func (l *loader) get(pkgName string) *Pkg {
 
-	if strings.Index(pkgName, pkgMod) != 0 {
+	dir := pkgDir(pkgName)
+	if dir == "" {
 		return nil
 	}
 
-	pkgName = strings.Replace(pkgName, pkgMod, "", 1)
-	base, _ := os.Getwd()
-	dir := base + pkgName
+	l.lock.Lock()
+	e, ok := l.pkgs[dir]
+	if !ok {
+		e = &pkgEntry{}
+		l.pkgs[dir] = e
+	}
+	l.lock.Unlock()
+
+	e.once.Do(func() {
+		e.pkg = l.load(pkgName, dir)
+	})
+	return e.pkg
+}
 
-	if pkg, ok := pkgs[dir]; ok {
-		return pkg
+func (l *loader) load(pkgName, dir string) *Pkg {
+	key := l.cacheKey(pkgName, dir)
+	if c := readCache(key); c != nil && l.validCache(pkgName, c) {
+		return &Pkg{
+			Dir:    dir,
+			Path:   pkgName,
+			Name:   c.Name,
+			loader: l,
+			cache:  c,
+			key:    key,
+		}
 	}
-	pkgMap, err := parser.ParseDir(token.NewFileSet(), dir, nil, parser.ParseComments)
+
+	w := l.pool()
+	w <- struct{}{}
+	pkgMap, err := parser.ParseDir(fset, dir, l.matchFile(dir), parser.ParseComments)
+	<-w
 	if err != nil {
 		return nil
 	}
 
-	for name, pkg := range pkgMap {
+	names := []string{}
+	for name := range pkgMap {
 		slp := stringify.ToStringSlice(name, "_")
 		if slp[len(slp)-1] == "test" {
 			continue
 		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil
+	}
 
-		pkg := &Pkg{
-			pkg:  pkg,
-			Name: pkg.Name,
+	// 多个包时优先与目录同名的包, 其次按名字排序
+	sort.Strings(names)
+	name := names[0]
+	for _, n := range names {
+		if n == filepath.Base(dir) {
+			name = n
 		}
+	}
 
-		pkgs[dir] = pkg
-		return pkg
+	pkg := &Pkg{
+		Dir:    dir,
+		Path:   pkgName,
+		pkg:    pkgMap[name],
+		Name:   name,
+		loader: l,
+		key:    key,
 	}
+	if key != "" {
+		l.lock.Lock()
+		l.parsed = append(l.parsed, pkg)
+		l.lock.Unlock()
+	}
+	return pkg
+}
 
-	return nil
+// 并发执行 n 个任务, 解析目录的并发数由 loader 的 workers 限制
+func parallel(n int, f func(i int)) {
+	wg := sync.WaitGroup{}
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer wg.Done()
+			f(i)
+		}(i)
+	}
+	wg.Wait()
 }
 
 func (pkg *Pkg) SetPkgs() *Pkg {
-	if pkg.pkgs != nil {
-		return pkg
-	}
+	pkg.pkgsOnce.Do(func() {
+		if pkg.cache != nil {
+			pkg.setCachedPkgs()
+			return
+		}
+
+		files := pkg.Files()
+		imports := make([][]*Pkg, len(files))
+		parallel(len(files), func(i int) {
+			f := pkg.pkg.Files[files[i]]
+			imports[i] = make([]*Pkg, len(f.Imports))
+			parallel(len(f.Imports), func(j int) {
+				imports[i][j] = pkg.loader.get(strings.Trim(f.Imports[j].Path.Value, "\""))
+			})
+		})
+
+		pkg.pkgs = make(map[string]map[string]*Pkg)
+		pkg.imports = make(map[string]map[string]string)
+		for i, file := range files {
+			pkg.pkgs[file] = make(map[string]*Pkg)
+			pkg.imports[file] = make(map[string]string)
+			for j, p := range pkg.pkg.Files[file].Imports {
+				importPath := strings.Trim(p.Path.Value, "\"")
+				name := path.Base(importPath)
+				mpkg := imports[i][j]
+				if mpkg != nil {
+					name = mpkg.Name
+				}
+				if p.Name != nil {
+					name = p.Name.Name
+				}
+				pkg.imports[file][name] = importPath
+				if mpkg != nil {
+					pkg.pkgs[file][name] = mpkg
+				}
+			}
+		}
+	})
+	return pkg
+}
+
+func (pkg *Pkg) setCachedPkgs() {
 	pkg.pkgs = make(map[string]map[string]*Pkg)
-	for file, f := range pkg.pkg.Files {
+	pkg.imports = pkg.cache.Imports
+	for file, m := range pkg.cache.Imports {
 		pkg.pkgs[file] = make(map[string]*Pkg)
-		for _, p := range f.Imports {
-			pkgName := strings.Trim(p.Path.Value, "\"")
-			mpkg := GetPkg(pkgName)
-			if mpkg == nil {
-				continue
-			}
-			name := mpkg.Name
-			if p.Name != nil {
-				name = p.Name.Name
+		for name, p := range m {
+			if mpkg := pkg.loader.get(p); mpkg != nil {
+				pkg.pkgs[file][name] = mpkg
 			}
-			pkg.pkgs[file][name] = mpkg
 		}
 	}
-	return pkg
 }
 
 func (pkg *Pkg) GetPkg(file, name string) *Pkg {
@@ -118,26 +298,60 @@ func (pkg *Pkg) GetPkg(file, name string) *Pkg {
 	return p
 }
 
+// 文件中包名对应的导入路径
+func (pkg *Pkg) importPath(file, name string) string {
+	return pkg.SetPkgs().imports[file][name]
+}
+
 func (pkg *Pkg) SetStru() *Pkg {
-	if pkg.stru != nil {
-		return pkg
-	}
+	pkg.struOnce.Do(pkg.setStru)
+	return pkg
+}
 
+func (pkg *Pkg) setStru() {
 	pkg.stru = make(map[string]*TypeSpec)
-	for file, f := range pkg.pkg.Files {
-		for _, p := range f.Scope.Objects {
-			if p.Kind != ast.Typ {
-				continue
+	if pkg.cache != nil {
+		for name, c := range pkg.cache.Stru {
+			if t := pkg.decodeType(c); t != nil {
+				pkg.stru[name] = t.TypeSpec
 			}
-			typeSpec, _ := p.Decl.(*ast.TypeSpec)
-			pkg.stru[typeSpec.Name.Name] = ParseTypeSpec(typeSpec, pkg, file)
-			if pkg.stru[typeSpec.Name.Name] == nil {
+		}
+		return
+	}
+
+	for _, file := range pkg.Files() {
+		for _, d := range pkg.pkg.Files[file].Decls {
+			genDecl, ok := d.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
 				continue
 			}
-			pkg.stru[typeSpec.Name.Name].Name = typeSpec.Name.Name
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				if _, ok := pkg.stru[typeSpec.Name.Name]; ok {
+					continue
+				}
+				pkg.stru[typeSpec.Name.Name] = ParseTypeSpec(typeSpec, pkg, file)
+				if pkg.stru[typeSpec.Name.Name] == nil {
+					continue
+				}
+				pkg.stru[typeSpec.Name.Name].Name = typeSpec.Name.Name
+				// 单独声明的类型, 注释在 GenDecl 上
+				if typeSpec.Doc == nil && genDecl.Lparen == 0 && genDecl.Doc != nil {
+					pkg.stru[typeSpec.Name.Name].Doc = commentLines(genDecl.Doc)
+				}
+			}
 		}
 	}
-	return pkg
+}
+
+// 按文件名排序, 保证输出顺序稳定
+func (pkg *Pkg) Files() []string {
+	files := make([]string, 0, len(pkg.pkg.Files))
+	for file := range pkg.pkg.Files {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files
 }
 
 func (pkg *Pkg) GetStru(name string) *TypeSpec {
@@ -149,25 +363,48 @@ func (pkg *Pkg) GetStru(name string) *TypeSpec {
 }
 
 func GetApis(pacakges ...string) []*DocApi {
-	apis := []*DocApi{}
-	for _, p := range pacakges {
-		pkg := GetPkg(p)
+	return defaultLoader().getApis(pacakges...)
+}
+
+func (l *loader) getApis(pacakges ...string) []*DocApi {
+	list := make([][]*DocApi, len(pacakges))
+	parallel(len(pacakges), func(i int) {
+		pkg := l.get(pacakges[i])
 		if pkg == nil {
-			continue
+			return
 		}
-		for file, f := range pkg.pkg.Files {
-			for _, f := range f.Decls {
-				funcDecl, ok := f.(*ast.FuncDecl)
-				if !ok {
-					continue
-				}
-				if funcDecl.Doc == nil {
-					continue
-				}
-				api := NewDocApi(funcDecl.Doc, pkg, file)
-				if api != nil {
-					apis = append(apis, api)
-				}
+		pkg.SetPkgs().SetStru()
+		list[i] = pkg.GetApis()
+	})
+	l.saveCache()
+
+	apis := []*DocApi{}
+	for _, l := range list {
+		apis = append(apis, l...)
+	}
+	return apis
+}
+
+func (pkg *Pkg) GetApis() []*DocApi {
+	apis := []*DocApi{}
+	if pkg.cache != nil {
+		for _, c := range pkg.cache.Apis {
+			apis = append(apis, pkg.decodeApi(c))
+		}
+		return apis
+	}
+	for _, file := range pkg.Files() {
+		for _, f := range pkg.pkg.Files[file].Decls {
+			funcDecl, ok := f.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			if funcDecl.Doc == nil {
+				continue
+			}
+			api := NewDocApi(funcDecl.Doc, pkg, file)
+			if api != nil {
+				apis = append(apis, api)
 			}
 		}
 	}
@@ -0,0 +1,576 @@
+package doc
+
+import (
+	"fmt"
+	"go/build"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestBuildConstraints(t *testing.T) {
+	defer SetBuildTags()
+	defer SetPlatform(build.Default.GOOS, build.Default.GOARCH)
+
+	SetPlatform("linux", "amd64")
+	apis := GetApis("github.com/uccu/go-doc/testdata/build")
+	if len(apis) != 1 || apis[0].Router != "/user/info" {
+		t.Errorf("apis without tags = %d", len(apis))
+	}
+	if u := GetPkg("github.com/uccu/go-doc/testdata/build").GetStru("User"); u == nil || len(u.Value) != 1 {
+		t.Error("file for another GOOS was parsed")
+	}
+
+	SetBuildTags("internal")
+	SetPlatform("windows", "amd64")
+	apis = GetApis("github.com/uccu/go-doc/testdata/build")
+	if len(apis) != 2 {
+		t.Errorf("apis with internal tag = %d", len(apis))
+	}
+	if u := GetPkg("github.com/uccu/go-doc/testdata/build").GetStru("User"); u == nil || len(u.Value) != 2 {
+		t.Error("file for windows was not parsed")
+	}
+}
+
+func TestBuildTarget(t *testing.T) {
+	pkgName := "github.com/uccu/go-doc/testdata/build"
+	linux := NewSSDoc(SSDocInfo{}, nil).Platform("linux", "amd64")
+	windows := NewSSDoc(SSDocInfo{}, nil).BuildTags("internal").Platform("windows", "amd64")
+
+	// 交替加载, 两个文档的编译条件互不影响
+	linux.AddPacakges(pkgName)
+	windows.AddPacakges(pkgName)
+	if n := len(linux.Apis["default"]); n != 1 {
+		t.Errorf("linux apis = %d", n)
+	}
+	if n := len(windows.Apis["default"]); n != 2 {
+		t.Errorf("windows apis = %d", n)
+	}
+	if u := linux.lookupType(pkgName + ".User"); u == nil || len(u.Value) != 1 {
+		t.Error("linux document got the windows package")
+	}
+	if u := windows.lookupType(pkgName + ".User"); u == nil || len(u.Value) != 2 {
+		t.Error("windows document got the linux package")
+	}
+}
+
+func TestExpandPackages(t *testing.T) {
+	base := "github.com/uccu/go-doc/testdata/pattern"
+	list := ExpandPackages([]string{base + "/...", "./testdata/pattern/model"}, []string{base + "/adm*/..."})
+	want := []string{base + "/order", base + "/user", base + "/model"}
+	if len(list) != len(want) {
+		t.Fatalf("packages = %v", list)
+	}
+	for i := range want {
+		if list[i] != want[i] {
+			t.Errorf("packages[%d] = %s, want %s", i, list[i], want[i])
+		}
+	}
+
+	list = ExpandPackages([]string{"./testdata/pattern/..."}, []string{base + "/admin/..."})
+	if len(list) != 2 {
+		t.Errorf("packages = %v", list)
+	}
+}
+
+func TestApiOrder(t *testing.T) {
+	ssdoc := NewSSDoc(SSDocInfo{}, nil).AddPacakges("github.com/uccu/go-doc/testdata/order")
+	apis := ssdoc.Apis["default"]
+	want := []string{"/a", "/b", "/c", "/d"}
+	if len(apis) != len(want) {
+		t.Fatalf("apis = %d", len(apis))
+	}
+	for i := range want {
+		if apis[i].Path != want[i] {
+			t.Errorf("apis[%d] = %s, want %s", i, apis[i].Path, want[i])
+		}
+	}
+}
+
+func TestConcurrentGetApis(t *testing.T) {
+	defer SetWorkers(runtime.NumCPU())
+	SetWorkers(2)
+
+	pattern := []string{"github.com/uccu/go-doc/testdata/pattern/..."}
+	want := len(GetApis(ExpandPackages(pattern, nil)...))
+	parallel(8, func(int) {
+		if n := len(GetApis(ExpandPackages(pattern, nil)...)); n != want {
+			t.Errorf("apis = %d, want %d", n, want)
+		}
+	})
+}
+
+func TestConcurrentSetWorkers(t *testing.T) {
+	// 解析过程中修改并发数
+	l := newLoader(build.Default, 1)
+	pattern := []string{"github.com/uccu/go-doc/testdata/pattern/..."}
+	parallel(8, func(i int) {
+		l.setWorkers(i%3 + 1)
+		if len(l.getApis(l.expandPackages(pattern, nil)...)) == 0 {
+			t.Error("no apis")
+		}
+	})
+}
+
+func TestDiagnostics(t *testing.T) {
+	ssdoc := NewSSDoc(SSDocInfo{}, nil).AddPacakges("github.com/uccu/go-doc/testdata/diag")
+
+	want := []struct {
+		line       int
+		sev        Severity
+		annotation string
+	}{
+		{10, SeverityError, "Body"},
+		{11, SeverityWarning, "Succes"},
+		{13, SeverityError, "Fail"},
+		{4, SeverityError, ""},
+	}
+	diags := ssdoc.Diagnostics()
+	if len(diags) != len(want) {
+		t.Fatalf("diagnostics = %v", diags)
+	}
+	for i, w := range want {
+		d := diags[i]
+		if d.Pos.Line != w.line || d.Severity != w.sev || d.Annotation != w.annotation {
+			t.Errorf("diagnostics[%d] = %s", i, d)
+		}
+	}
+
+	if u := ssdoc.Apis["default"][0].Success[0].Value; len(u.Value) != 2 {
+		t.Error("struct with an invalid tag was dropped")
+	}
+}
+
+func TestUnresolvedType(t *testing.T) {
+	ssdoc := NewSSDoc(SSDocInfo{}, nil).AddPacakges("github.com/uccu/go-doc/testdata/unresolved")
+
+	// 共用的类型只报告一次, 标准库的类型不报告
+	diags := ssdoc.Diagnostics()
+	if len(diags) != 1 || diags[0].Message != "unresolved type missing.Owner" || diags[0].Pos.Line != 11 {
+		t.Errorf("diagnostics = %v", diags)
+	}
+}
+
+func TestStrict(t *testing.T) {
+	pkgName := "github.com/uccu/go-doc/testdata/strict"
+	ssdoc := NewSSDoc(SSDocInfo{}, map[SSDocServerId]*SSDocServer{"http": {}}).AddPacakges(pkgName)
+	if ssdoc.Err() != nil {
+		t.Error("diagnostics returned as error without strict mode")
+	}
+
+	err, ok := ssdoc.Strict(true).Err().(DiagnosticError)
+	if !ok || len(err) != 5 {
+		t.Fatalf("strict error = %v", err)
+	}
+	// 组合类型中无法解析的类型与单独的类型一样是错误
+	for i, a := range []string{"Router", "Router", "Server", "Success", "Success"} {
+		if err[i].Annotation != a {
+			t.Errorf("error[%d] = %s", i, err[i])
+		}
+	}
+	if ssdoc.Export(t.TempDir()) == nil {
+		t.Error("export succeeded in strict mode")
+	}
+}
+
+func TestGodocFallback(t *testing.T) {
+	ssdoc := NewSSDoc(SSDocInfo{}, nil).AddPacakges("github.com/uccu/go-doc/testdata/godoc")
+	apis := ssdoc.Apis["default"]
+	if len(apis) != 2 {
+		t.Fatalf("apis = %d", len(apis))
+	}
+
+	if a := apis[0]; a.Name != "Get 获取用户信息." || a.Description != "用户不存在时返回 404.\n\n需要登录." {
+		t.Errorf("api = %q %q", a.Name, a.Description)
+	}
+	if a := apis[1]; a.Name != "修改用户" || a.Description != "只能修改自己的信息" {
+		t.Errorf("api = %q %q", a.Name, a.Description)
+	}
+	if d := apis[0].Success[0].Value.Description; d != "User 用户信息.\n\n昵称可以重复." {
+		t.Errorf("type description = %q", d)
+	}
+}
+
+func TestParam(t *testing.T) {
+	ssdoc := NewSSDoc(SSDocInfo{}, nil).AddPacakges("github.com/uccu/go-doc/testdata/param")
+	api := ssdoc.Apis["default"][0]
+
+	want := []struct {
+		name, in, typ string
+		required      bool
+		desc          string
+	}{
+		{"uid", "path", "int64", true, "用户"},
+		{"status", "query", "int", false, "订单 状态"},
+		{"tags", "query", "array", false, ""},
+		{"X-Token", "header", "string", true, "令牌"},
+		{"note", "form", "string", true, "备注"},
+		{"page", "query", "int", false, "页码"},
+		{"size", "query", "int", false, ""},
+		{"sort", "query", "string", false, "排序"},
+		{"q", "query", "string", false, "search keyword here"},
+	}
+	if len(api.Param) != len(want) {
+		t.Fatalf("params = %d", len(api.Param))
+	}
+	for i, w := range want {
+		p := api.Param[i]
+		if p.Name != w.name || p.In != w.in || p.Value.TypeName != w.typ || p.Required != w.required || p.Description != w.desc {
+			t.Errorf("params[%d] = %+v", i, p)
+		}
+	}
+	if d := ssdoc.Diagnostics(); len(d) != 1 || d[0].Annotation != "Param" || d[0].Pos.Line != 14 {
+		t.Errorf("diagnostics = %v", d)
+	}
+
+	op := ssdoc.OpenAPI().Paths["/user/{uid}/orders"]["get"]
+	if len(op.Parameters) != 8 || op.Parameters[1].Schema.Type != "integer" || op.Parameters[2].Schema.Items.Type != "string" {
+		t.Errorf("parameters = %+v", op.Parameters)
+	}
+	form := op.RequestBody.Content["application/x-www-form-urlencoded"].Schema
+	if form.Properties["note"].Description != "备注" || len(form.Required) != 1 {
+		t.Errorf("form = %+v", form)
+	}
+}
+
+func TestRequestSections(t *testing.T) {
+	ssdoc := NewSSDoc(SSDocInfo{}, nil).AddPacakges("github.com/uccu/go-doc/testdata/request")
+
+	names := func(list []*SSDocTypeWithKey) string {
+		s := []string{}
+		for _, f := range list {
+			s = append(s, f.paramName())
+		}
+		return strings.Join(s, ",")
+	}
+
+	apis := map[string]*SSDocApi{}
+	for _, api := range ssdoc.Apis["default"] {
+		apis[api.Path] = api
+	}
+
+	req := apis["/user/:id"].Request
+	if req == nil {
+		t.Fatal("missing request sections")
+	}
+	for section, w := range map[string]struct {
+		list []*SSDocTypeWithKey
+		want string
+	}{
+		"path":   {req.Path, "id"},
+		"query":  {req.Query, "page"},
+		"header": {req.Header, "X-Token"},
+		"form":   {req.Form, ""},
+		"body":   {req.Body, "name,age"},
+	} {
+		if got := names(w.list); got != w.want {
+			t.Errorf("%s = %s, want %s", section, got, w.want)
+		}
+	}
+
+	req = apis["/user/:uid/list"].Request
+	if names(req.Path) != "Uid" || names(req.Query) != "page,sort" || req.Body != nil {
+		t.Errorf("list request = %+v", req)
+	}
+
+	op := ssdoc.OpenAPI().Paths["/user/{id}"]["post"]
+	in := []string{}
+	for _, p := range op.Parameters {
+		in = append(in, p.In+":"+p.Name)
+	}
+	if got := strings.Join(in, ","); got != "path:id,query:page,header:X-Token" {
+		t.Errorf("parameters = %s", got)
+	}
+	body := op.RequestBody.Content["application/json"].Schema
+	if len(body.Properties) != 2 || body.Properties["name"] == nil || body.Properties["age"] == nil {
+		t.Errorf("body = %+v", body.Properties)
+	}
+}
+
+func TestPathParams(t *testing.T) {
+	ssdoc := NewSSDoc(SSDocInfo{}, nil).AddPacakges("github.com/uccu/go-doc/testdata/router")
+
+	apis := map[string]*SSDocApi{}
+	for _, api := range ssdoc.Apis["default"] {
+		apis[api.Name] = api
+	}
+
+	get := apis["用户信息"]
+	if get.Template != "/user/{id}" || len(get.PathParams) != 1 || get.PathParams[0].Value.TypeName != "int64" || get.PathParams[0].Description != "用户 id" {
+		t.Errorf("get = %s %+v", get.Template, get.PathParams)
+	}
+
+	download := apis["下载文件"]
+	if p := download.PathParams; download.Template != "/files/{path}" || len(p) != 1 || !p[0].Wildcard || p[0].Description != "文件路径" {
+		t.Errorf("download = %s %+v", download.Template, p)
+	}
+
+	for router, want := range map[string]string{
+		"/files/*path":           "/files/{path...}",
+		"/files/{path...}":       "/files/{path...}",
+		"/user/:uid/order/{oid}": "/user/{uid}/order/{oid}",
+		"/user/{id:[0-9]+}":      "/user/{id}",
+	} {
+		if got := MuxPattern(router); got != want {
+			t.Errorf("MuxPattern(%s) = %s, want %s", router, got, want)
+		}
+	}
+
+	order := apis["订单信息"]
+	if p := order.PathParams; len(p) != 2 || p[0].Value == nil || p[1].Value != nil {
+		t.Errorf("order = %+v", p)
+	}
+
+	want := []string{
+		"@Router: path parameter oid has no matching @Rest field or @Param",
+		"@Rest: field Oid is bound to path parameter order_id which is not in @Router /user/:uid/order/:oid",
+		"@Param: path parameter name is not in @Router /user/:uid/order/:oid",
+	}
+	diags := ssdoc.Diagnostics()
+	if len(diags) != len(want) {
+		t.Fatalf("diagnostics = %v", diags)
+	}
+	for i, w := range want {
+		if d := diags[i]; d.Severity != SeverityWarning || !strings.HasSuffix(d.String(), w) {
+			t.Errorf("diagnostics[%d] = %s", i, d)
+		}
+	}
+
+	op := ssdoc.OpenAPI().Paths["/user/{uid}/order/{oid}"]["get"]
+	in := []string{}
+	for _, p := range op.Parameters {
+		in = append(in, p.In+":"+p.Name)
+	}
+	if got := strings.Join(in, ","); got != "path:uid,path:order_id,path:name,path:oid" {
+		t.Errorf("parameters = %s", got)
+	}
+}
+
+func TestCompositeTypes(t *testing.T) {
+	ssdoc := NewSSDoc(SSDocInfo{}, nil).AddPacakges("github.com/uccu/go-doc/testdata/composite")
+	api := ssdoc.Apis["default"][0]
+
+	if api.Body == nil || api.Body.Type != SliceType || api.Body.Value[0].TypeName != "int64" {
+		t.Errorf("body = %+v", api.Body)
+	}
+
+	want := map[string]string{
+		"list":  "[]Item",
+		"index": "map[string]Item",
+		"owner": "User",
+		"total": "int64",
+		"title": "string",
+	}
+	if len(api.Success) != len(want) {
+		t.Fatalf("success = %d", len(api.Success))
+	}
+	for _, r := range api.Success {
+		if got := markdownType(r.Value); got != want[r.Key] {
+			t.Errorf("%s = %s, want %s", r.Key, got, want[r.Key])
+		}
+	}
+	if fields := fieldsOf(elemOf(api.Success[0].Value)); len(fields) != 2 || fields[1].name() != "owner" {
+		t.Errorf("list fields = %+v", fields)
+	}
+
+	if d := ssdoc.Diagnostics(); len(d) != 1 || d[0].Annotation != "Fail" || d[0].Message != "unsupported type error" {
+		t.Errorf("diagnostics = %v", d)
+	}
+
+	schema := ssdoc.OpenAPI().Paths["/items"]["post"].Responses["200"].Content["application/json"].Schema
+	if schema.Properties["list"].Type != "array" || schema.Properties["total"].Type != "integer" || schema.Properties["index"].AdditionalProperties == nil {
+		t.Errorf("schema = %+v", schema.Properties)
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	ssdoc := NewSSDoc(SSDocInfo{}, nil).
+		Envelope("github.com/uccu/go-doc/testdata/envelope.Response", "").
+		AddPacakges("github.com/uccu/go-doc/testdata/envelope")
+
+	apis := map[string]*SSDocApi{}
+	for _, api := range ssdoc.Apis["default"] {
+		apis[api.Path] = api
+	}
+
+	keys := func(rets []*SSDocRet) string {
+		s := []string{}
+		for _, r := range rets {
+			s = append(s, fmt.Sprintf("%d:%s", r.Code, r.Key))
+		}
+		return strings.Join(s, ",")
+	}
+
+	get := apis["/user"]
+	if got := keys(get.Success); got != "200:code,200:message,200:data" {
+		t.Errorf("success = %s", got)
+	}
+	if got := keys(get.Fail); got != "400:message,400:code,500:code,500:message" {
+		t.Errorf("fail = %s", got)
+	}
+	if !get.Success[0].Envelope || get.Success[2].Envelope {
+		t.Errorf("envelope flags = %+v", get.Success)
+	}
+
+	code, v := get.Mock()
+	if m, ok := v.(map[string]interface{}); code != 200 || !ok || len(m) != 3 || m["data"] == nil {
+		t.Errorf("mock = %d %v", code, v)
+	}
+
+	if got := keys(apis["/ping"].Success); got != "200:status" {
+		t.Errorf("ping success = %s", got)
+	}
+	if d := ssdoc.Diagnostics(); len(d) != 0 {
+		t.Errorf("diagnostics = %v", d)
+	}
+
+	ssdoc = NewSSDoc(SSDocInfo{}, nil).Envelope("github.com/uccu/go-doc/testdata/envelope.Response", "result")
+	if d := ssdoc.Diagnostics(); len(d) != 1 || d[0].Message != "envelope type github.com/uccu/go-doc/testdata/envelope.Response has no field result" {
+		t.Errorf("diagnostics = %v", d)
+	}
+}
+
+func TestDefines(t *testing.T) {
+	ssdoc := NewSSDoc(SSDocInfo{}, nil).AddPacakges("github.com/uccu/go-doc/testdata/define")
+
+	apis := map[string]*SSDocApi{}
+	for _, api := range ssdoc.Apis["default"] {
+		apis[api.Path] = api
+	}
+
+	get := apis["/user"]
+	fail := []string{}
+	for _, r := range get.Fail {
+		fail = append(fail, fmt.Sprintf("%d:%s:%s:%s", r.Code, r.Key, markdownType(r.Value), r.Description))
+	}
+	if got := strings.Join(fail, ","); got != "500:msg:AuthErr:,401:msg:AuthErr:未登录,404:msg:string:不存在" {
+		t.Errorf("fail = %s", got)
+	}
+
+	header := []string{}
+	for _, h := range get.Header {
+		header = append(header, fmt.Sprintf("%s:%v:%s", h.Name, h.Required, h.Description))
+	}
+	if got := strings.Join(header, ","); got != "X-Trace:true:覆盖,Authorization:true:token" {
+		t.Errorf("header = %s", got)
+	}
+
+	want := []string{
+		"@DefineFail: invalid arguments: broken 401",
+		"@FailRef: undefined fail set nothing",
+	}
+	diags := ssdoc.Diagnostics()
+	if len(diags) != len(want) {
+		t.Fatalf("diagnostics = %v", diags)
+	}
+	for i, w := range want {
+		if !strings.HasSuffix(diags[i].String(), w) {
+			t.Errorf("diagnostics[%d] = %s", i, diags[i])
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	ssdoc := New(DocConf{
+		Pkgs: []string{"github.com/uccu/go-doc/testdata/middleware"},
+		Middlewares: map[string]*MiddlewareConf{
+			"tenant": {
+				Headers: []*SSDocHeader{{Name: "X-Tenant", Required: true}},
+				Fail: []*MiddlewareFail{
+					{Code: 403, Key: "msg", Type: "string"},
+					{Code: 401, Key: "msg", Type: "github.com/uccu/go-doc/testdata/middleware.AuthErr"},
+				},
+				Tags: []string{"tenant", "login"},
+			},
+		},
+	}).SSDoc()
+
+	apis := map[string]*SSDocApi{}
+	for _, api := range ssdoc.Apis["default"] {
+		apis[api.Path] = api
+	}
+	if len(apis) != 2 {
+		t.Fatalf("apis = %v", apis)
+	}
+
+	get := apis["/user"]
+	header := []string{}
+	for _, h := range get.Header {
+		header = append(header, fmt.Sprintf("%s:%v", h.Name, h.Required))
+	}
+	if got := strings.Join(header, ","); got != "Authorization:false,X-Tenant:true" {
+		t.Errorf("header = %s", got)
+	}
+	fail := []string{}
+	for _, r := range get.Fail {
+		fail = append(fail, fmt.Sprintf("%d:%s:%s", r.Code, markdownType(r.Value), r.Description))
+	}
+	if got := strings.Join(fail, ","); got != "401:AuthErr:未登录,403:string:" {
+		t.Errorf("fail = %s", got)
+	}
+	if got := strings.Join(get.Tag, ","); got != "user,login,tenant" {
+		t.Errorf("tag = %s", got)
+	}
+
+	if d := ssdoc.Diagnostics(); len(d) != 1 || d[0].Annotation != "Use" || d[0].Message != "unknown middleware nothing" {
+		t.Errorf("diagnostics = %v", d)
+	}
+}
+
+func TestMiddlewarePackage(t *testing.T) {
+	// 中间件在单独的包中, 通过通配符加载
+	ssdoc := NewSSDoc(SSDocInfo{}, nil).AddPacakges("./testdata/gin/...")
+	if d := ssdoc.Diagnostics(); len(d) != 0 {
+		t.Errorf("diagnostics = %v", d)
+	}
+	apis := ssdoc.Apis["default"]
+	if len(apis) != 1 || len(apis[0].Header) != 1 || apis[0].Header[0].Name != "Authorization" || len(apis[0].Fail) != 1 || len(apis[0].Tag) != 0 {
+		t.Errorf("apis = %+v", apis)
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	ssdoc := NewSSDoc(SSDocInfo{}, map[SSDocServerId]*SSDocServer{"api": {Url: "http://localhost"}}).
+		AddPacakges("github.com/uccu/go-doc/testdata/defaults")
+
+	apis := map[string]*SSDocApi{}
+	for _, list := range ssdoc.Apis {
+		for _, api := range list {
+			apis[api.Path] = api
+		}
+	}
+	if len(apis) != 4 {
+		t.Fatalf("apis = %v", apis)
+	}
+
+	format := func(api *SSDocApi) string {
+		header := []string{}
+		for _, h := range api.Header {
+			header = append(header, fmt.Sprintf("%s:%v", h.Name, h.Required))
+		}
+		return fmt.Sprintf("%s %s %s %s %s", api.Category, api.Server, strings.Join(api.Method, ","), strings.Join(api.Tag, ","), strings.Join(header, ","))
+	}
+	want := map[string]string{
+		"/api/v1/user/:id": "用户 api get user,profile Authorization:true",
+		"/api/v1/user":     "其它 api put user Authorization:true",
+		"/admin/users":     "用户 api post,put user,admin Authorization:false",
+		"/orders/list":     "用户 api get user,order Authorization:true",
+	}
+	for path, w := range want {
+		api, ok := apis[path]
+		if !ok {
+			t.Errorf("missing %s", path)
+			continue
+		}
+		if got := format(api); got != w {
+			t.Errorf("%s = %s, want %s", path, got, w)
+		}
+	}
+	if d := ssdoc.Diagnostics(); len(d) != 0 {
+		t.Errorf("diagnostics = %v", d)
+	}
+
+	if api := NewSSDoc(SSDocInfo{}, nil).AddApi(&DocApi{Router: "/x"}).Apis["default"][0]; api.Method[0] != DefaultMethod {
+		t.Errorf("default method = %v", api.Method)
+	}
+}
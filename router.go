@@ -0,0 +1,69 @@
+package doc
+
+import "strings"
+
+type routeParam struct {
+	name     string
+	wildcard bool
+}
+
+// 从路由中取出路径参数, 支持 /user/:id, /user/{id} 与 /files/*path
+func PathParams(router string) []string {
+	params := []string{}
+	for _, p := range routeParams(router) {
+		params = append(params, p.name)
+	}
+	return params
+}
+
+func routeParams(router string) []routeParam {
+	params := []routeParam{}
+	for _, seg := range strings.Split(router, "/") {
+		if p, ok := pathParam(seg); ok {
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+// *path 与 {path...} 匹配剩余的全部路径
+func pathParam(seg string) (routeParam, bool) {
+	if len(seg) > 1 && (seg[0] == ':' || seg[0] == '*') {
+		return routeParam{name: seg[1:], wildcard: seg[0] == '*'}, true
+	}
+	if len(seg) > 2 && seg[0] == '{' && seg[len(seg)-1] == '}' {
+		name := seg[1 : len(seg)-1]
+		if i := strings.IndexAny(name, ":"); i >= 0 {
+			name = name[:i]
+		}
+		if strings.HasSuffix(name, "...") {
+			return routeParam{name: strings.TrimSuffix(name, "..."), wildcard: true}, true
+		}
+		return routeParam{name: name}, true
+	}
+	return routeParam{}, false
+}
+
+// 统一为 OpenAPI 风格的路径模板, 如 /user/:id 转为 /user/{id}
+func PathTemplate(router string) string {
+	segs := strings.Split(router, "/")
+	for i, seg := range segs {
+		if p, ok := pathParam(seg); ok {
+			segs[i] = "{" + p.name + "}"
+		}
+	}
+	return strings.Join(segs, "/")
+}
+
+// 转为 Go 1.22 ServeMux 的路由, 匹配剩余路径的参数保留 ..., 如 /files/*path 转为 /files/{path...}
+func MuxPattern(router string) string {
+	segs := strings.Split(router, "/")
+	for i, seg := range segs {
+		if p, ok := pathParam(seg); ok && p.wildcard {
+			segs[i] = "{" + p.name + "...}"
+		} else if ok {
+			segs[i] = "{" + p.name + "}"
+		}
+	}
+	return strings.Join(segs, "/")
+}
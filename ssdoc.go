@@ -2,18 +2,28 @@ package doc
 
 import (
 	"encoding/json"
-	"io/ioutil"
+	"fmt"
+	"go/token"
 	"os"
-	"path"
-	"runtime"
+	"sort"
 	"strings"
 )
 
 type SSDoc struct {
-	Version string                          `json:"version"` // ssdoc api版本
-	Info    SSDocInfo                       `json:"info"`    // 文档信息
-	Servers map[SSDocServerId]*SSDocServer  `json:"servers"` // 服务信息
-	Apis    map[SSDocCategoryId][]*SSDocApi `json:"apis"`    // 接口信息
+	Version     string                          `json:"version"` // ssdoc api版本
+	Info        SSDocInfo                       `json:"info"`    // 文档信息
+	Servers     map[SSDocServerId]*SSDocServer  `json:"servers"` // 服务信息
+	Apis        map[SSDocCategoryId][]*SSDocApi `json:"apis"`    // 接口信息
+	exclude     []string
+	diags       []*Diagnostic
+	seen        map[string]bool
+	strict      bool
+	routes      map[string]token.Position
+	types       map[string]string
+	headers     []*SSDocHeader
+	envelope    []*SSDocTypeWithKey
+	middlewares map[string]*middleware
+	loader      *loader
 }
 
 type SSDocCategoryId string
@@ -32,8 +42,10 @@ type SSDocServer struct {
 
 type SSDocApi struct {
 	Name        string            `json:"name"`                  // 名称
-	Description string            `json:"description,omitempty"` // 描述
+	Description string            `json:"description,omitempty"` // 描述, Markdown
 	Path        string            `json:"path"`                  // 路径
+	Template    string            `json:"template,omitempty"`    // 统一为 /user/{id} 形式的路径
+	PathParams  []*SSDocPathParam `json:"pathParams,omitempty"`  // 路径参数
 	Method      []string          `json:"method,omitempty"`      // 请求方式
 	Type        string            `json:"type"`                  // http/ws
 	Category    SSDocCategoryId   `json:"category"`              // 分类
@@ -41,10 +53,14 @@ type SSDocApi struct {
 	Tag         []string          `json:"tag,omitempty"`         // 标签
 	Accept      []string          `json:"accept,omitempty"`      // 请求返回的类型,json/xml等
 	Header      []*SSDocHeader    `json:"header,omitempty"`      // 请求头
+	Param       []*SSDocParam     `json:"param,omitempty"`       // 单独声明的参数
 	Rest        *SSDocTypeWithKey `json:"rest,omitempty"`        // REST参数
 	Body        *SSDocTypeWithKey `json:"body,omitempty"`        // 请求体参数
+	Request     *SSDocRequest     `json:"request,omitempty"`     // 按位置拆分的 Rest 与 Body 字段
 	Success     []*SSDocRet       `json:"success,omitempty"`     // 成功返回内容
 	Fail        []*SSDocRet       `json:"fail,omitempty"`        // 失败返回内容
+	Extensions  map[string]any    `json:"extensions,omitempty"`  // 自定义注解的内容
+	order       int
 }
 
 type SSDocHeader struct {
@@ -53,10 +69,35 @@ type SSDocHeader struct {
 	Required    bool   `json:"required,omitempty"`    // 是否必须
 }
 
+type SSDocParam struct {
+	Name        string            `json:"name"`                  // 名称
+	In          string            `json:"in"`                    // 位置, query/path/header/cookie/form
+	Description string            `json:"description,omitempty"` // 描述
+	Required    bool              `json:"required,omitempty"`    // 是否必须
+	Value       *SSDocTypeWithKey `json:"value"`                 // 类型
+}
+
+type SSDocPathParam struct {
+	Name        string            `json:"name"`                  // 名称
+	Wildcard    bool              `json:"wildcard,omitempty"`    // 匹配剩余的全部路径
+	Description string            `json:"description,omitempty"` // 描述
+	Value       *SSDocTypeWithKey `json:"value,omitempty"`       // 对应字段或 @Param 的类型
+}
+
+type SSDocRequest struct {
+	Path   []*SSDocTypeWithKey `json:"path,omitempty"`   // 路径参数
+	Query  []*SSDocTypeWithKey `json:"query,omitempty"`  // 查询参数
+	Header []*SSDocTypeWithKey `json:"header,omitempty"` // 请求头
+	Form   []*SSDocTypeWithKey `json:"form,omitempty"`   // 表单
+	Body   []*SSDocTypeWithKey `json:"body,omitempty"`   // 请求体
+}
+
 type SSDocTypeWithKey struct {
 	Key      string  `json:"key"`
 	Default  *string `json:"default,omitempty"`  // 默认值
 	Json     *string `json:"json,omitempty"`     // json key
+	Param    *string `json:"param,omitempty"`    // uri/query/form/header 标签中的名字
+	In       string  `json:"in,omitempty"`       // 标签指定的位置, path/query/form/header/body
 	Required bool    `json:"required,omitempty"` // 是否必须
 	*SSDocType
 }
@@ -70,29 +111,37 @@ type SSDocType struct {
 }
 
 type SSDocRet struct {
-	Code  int16             `json:"code"`
-	Key   string            `json:"key"`
-	Value *SSDocTypeWithKey `json:"value"`
+	Code        int16             `json:"code"`
+	Key         string            `json:"key"`
+	Value       *SSDocTypeWithKey `json:"value"`
+	Description string            `json:"description,omitempty"` // 描述
+	Envelope    bool              `json:"envelope,omitempty"`    // 来自全局的外层结构
 }
 
 func NewSSDoc(info SSDocInfo, servers map[SSDocServerId]*SSDocServer) *SSDoc {
 
-	ssdoc := &SSDoc{
+	return &SSDoc{
+		Version: version,
 		Info:    info,
 		Servers: servers,
 		Apis:    make(map[SSDocCategoryId][]*SSDocApi),
 	}
-
-	_, file, _, ok := runtime.Caller(1)
-	if ok {
-		version, _ := ioutil.ReadFile(path.Dir(file) + "/version")
-		ssdoc.Version = string(version)
-	}
-
-	return ssdoc
 }
 
 func (doc *SSDoc) AddApi(i *DocApi) *SSDoc {
+	if i.Middleware != "" {
+		doc.addMiddleware(i)
+		return doc
+	}
+	// 没有 @Router 的函数注释不是接口
+	if i.Router == "" {
+		doc.report(i.diags...)
+		return doc
+	}
+
+	doc.report(i.diags...)
+	doc.checkApi(i)
+	fail, header := doc.expandRefs(i)
 
 	api := &SSDocApi{
 		Name:        i.Summary,
@@ -104,19 +153,21 @@ func (doc *SSDoc) AddApi(i *DocApi) *SSDoc {
 		Server:      SSDocServerId(i.Server),
 		Tag:         i.Tag,
 		Accept:      i.Accept,
+		Extensions:  i.Extensions,
+		order:       i.Order,
 	}
 
 	if api.Method == nil {
-		api.Method = []string{"get"}
+		api.Method = []string{DefaultMethod}
 	}
 
 	if api.Category == "" {
 		api.Category = "default"
 	}
 
-	if i.Header != nil {
+	if header != nil {
 		api.Header = make([]*SSDocHeader, 0)
-		for _, h := range i.Header {
+		for _, h := range header {
 			header := &SSDocHeader{
 				Name:        h.Name,
 				Description: h.Remark,
@@ -126,59 +177,394 @@ func (doc *SSDoc) AddApi(i *DocApi) *SSDoc {
 		}
 	}
 
+	if i.Param != nil {
+		api.Param = make([]*SSDocParam, 0)
+		for _, p := range i.Param {
+			param := &SSDocParam{
+				Name:        p.Name,
+				In:          p.In,
+				Description: p.Remark,
+				Required:    p.Required,
+				Value:       doc.parseType(p.Value),
+			}
+			api.Param = append(api.Param, param)
+		}
+	}
+
 	if i.Rest != nil {
-		api.Rest = parseType(i.Rest)
+		api.Rest = doc.parseType(i.Rest)
 	}
 
 	if i.Body != nil {
-		api.Body = parseType(i.Body)
+		api.Body = doc.parseType(i.Body)
+	}
+	if api.Rest != nil || api.Body != nil {
+		api.Request = splitRequest(api)
+	}
+	if api.Path != "" {
+		api.Template = PathTemplate(api.Path)
+		api.PathParams = doc.pathParams(i, api)
 	}
 	if i.Success != nil {
 		api.Success = make([]*SSDocRet, 0)
 		for _, r := range i.Success {
 			ret := &SSDocRet{
-				Code:  r.Code,
-				Key:   r.Key,
-				Value: parseType(r.Value),
+				Code:        r.Code,
+				Key:         r.Key,
+				Value:       doc.parseType(r.Value),
+				Description: r.Description,
 			}
 			api.Success = append(api.Success, ret)
 		}
 	}
-	if i.Fail != nil {
+	if fail != nil {
 		api.Fail = make([]*SSDocRet, 0)
-		for _, r := range i.Fail {
+		for _, r := range fail {
 			ret := &SSDocRet{
-				Code:  r.Code,
-				Key:   r.Key,
-				Value: parseType(r.Value),
+				Code:        r.Code,
+				Key:         r.Key,
+				Value:       doc.parseType(r.Value),
+				Description: r.Description,
 			}
 			api.Fail = append(api.Fail, ret)
 		}
 	}
+	doc.use(i, api)
+	api.Header = doc.globalHeaders(api.Header)
+	if !i.NoEnvelope {
+		api.Success = doc.wrap(api.Success)
+		api.Fail = doc.wrap(api.Fail)
+	}
 
 	_, ok := doc.Apis[api.Category]
 	if !ok {
 		doc.Apis[api.Category] = make([]*SSDocApi, 0)
 	}
 
-	doc.Apis[api.Category] = append(doc.Apis[api.Category], api)
+	apis := append(doc.Apis[api.Category], api)
+	sort.SliceStable(apis, func(i, j int) bool {
+		return apis[i].order < apis[j].order
+	})
+	doc.Apis[api.Category] = apis
+
+	return doc
+}
+
+// 同一位置的相同问题只报告一次, 如多个接口共用的类型中的问题
+func (doc *SSDoc) report(diags ...*Diagnostic) {
+	if doc.seen == nil {
+		doc.seen = make(map[string]bool)
+	}
+	for _, d := range diags {
+		if key := d.String(); !doc.seen[key] {
+			doc.seen[key] = true
+			doc.diags = append(doc.diags, d)
+		}
+	}
+}
+
+// 解析过程中发现的问题, 如无法识别的注解与类型
+func (doc *SSDoc) Diagnostics() []*Diagnostic {
+	return doc.diags
+}
+
+// 严格模式下 Err 与 Export 在存在错误时失败
+func (doc *SSDoc) Strict(strict bool) *SSDoc {
+	doc.strict = strict
+	return doc
+}
+
+func (doc *SSDoc) Err() error {
+	if !doc.strict {
+		return nil
+	}
+	err := DiagnosticError{}
+	for _, d := range doc.diags {
+		if d.Severity == SeverityError {
+			err = append(err, d)
+		}
+	}
+	if len(err) == 0 {
+		return nil
+	}
+	return err
+}
+
+func (doc *SSDoc) checkApi(i *DocApi) {
+	if i.Server != "" {
+		if _, ok := doc.Servers[SSDocServerId(i.Server)]; !ok {
+			doc.report(&Diagnostic{
+				Severity:   SeverityError,
+				Message:    "unknown server " + i.Server,
+				Annotation: "Server",
+				Pos:        i.Position("Server"),
+			})
+		}
+	}
+
+	if i.Router == "" {
+		return
+	}
+	if doc.routes == nil {
+		doc.routes = make(map[string]token.Position)
+	}
+	for _, m := range i.Method {
+		key := strings.Join([]string{i.Type, i.Server, strings.ToUpper(m), i.Router}, " ")
+		pos := i.Position("Router")
+		if p, ok := doc.routes[key]; ok {
+			doc.report(&Diagnostic{
+				Severity:   SeverityError,
+				Message:    fmt.Sprintf("duplicate route %s %s, first declared at %s", strings.ToUpper(m), i.Router, p),
+				Annotation: "Router",
+				Pos:        pos,
+			})
+			continue
+		}
+		doc.routes[key] = pos
+	}
+}
+
+// 路由中的参数与 Rest 的 uri 字段, @Param path 一一对应
+func (doc *SSDoc) pathParams(i *DocApi, api *SSDocApi) []*SSDocPathParam {
+	warn := func(annotation, format string, a ...interface{}) {
+		doc.report(&Diagnostic{
+			Severity:   SeverityWarning,
+			Message:    fmt.Sprintf(format, a...),
+			Annotation: annotation,
+			Pos:        i.Position(annotation),
+		})
+	}
+
+	fields := make(map[string]*SSDocTypeWithKey)
+	if api.Request != nil {
+		for _, f := range api.Request.Path {
+			fields[strings.ToLower(f.paramName())] = f
+		}
+	}
+	params := make(map[string]*SSDocParam)
+	for _, p := range api.Param {
+		if p.In == "path" {
+			params[strings.ToLower(p.Name)] = p
+		}
+	}
+
+	list := []*SSDocPathParam{}
+	names := make(map[string]bool)
+	for _, r := range routeParams(api.Path) {
+		key := strings.ToLower(r.name)
+		names[key] = true
+		p := &SSDocPathParam{Name: r.name, Wildcard: r.wildcard}
+		if f, ok := fields[key]; ok {
+			p.Description, p.Value = f.Description, f
+		} else if v, ok := params[key]; ok {
+			p.Description, p.Value = v.Description, v.Value
+		} else {
+			warn("Router", "path parameter %s has no matching @Rest field or @Param", r.name)
+		}
+		list = append(list, p)
+	}
+
+	if api.Request != nil {
+		annotation := "Rest"
+		if i.Rest == nil {
+			annotation = "Body"
+		}
+		for _, f := range api.Request.Path {
+			if !names[strings.ToLower(f.paramName())] {
+				warn(annotation, "field %s is bound to path parameter %s which is not in @Router %s", f.Key, f.paramName(), api.Path)
+			}
+		}
+	}
+	for _, p := range api.Param {
+		if p.In == "path" && !names[strings.ToLower(p.Name)] {
+			warn("Param", "path parameter %s is not in @Router %s", p.Name, api.Path)
+		}
+	}
+	return list
+}
+
+func (doc *SSDoc) Exclude(globs ...string) *SSDoc {
+	doc.exclude = append(doc.exclude, globs...)
+	return doc
+}
+
+// 类型替换, 键为类型的完整路径或代码中的写法, 如 time.Time, 值为基础类型名
+func (doc *SSDoc) Override(types map[string]string) *SSDoc {
+	if doc.types == nil {
+		doc.types = make(map[string]string)
+	}
+	for k, v := range types {
+		doc.types[k] = v
+	}
+	return doc
+}
+
+// 所有接口共用的请求头, 接口中声明了同名请求头时以接口为准
+func (doc *SSDoc) Header(headers ...*SSDocHeader) *SSDoc {
+	doc.headers = append(doc.headers, headers...)
+	return doc
+}
+
+func (doc *SSDoc) globalHeaders(list []*SSDocHeader) []*SSDocHeader {
+	headers := []*SSDocHeader{}
+	for _, g := range doc.headers {
+		declared := false
+		for _, h := range list {
+			if strings.EqualFold(h.Name, g.Name) {
+				declared = true
+			}
+		}
+		if !declared {
+			h := *g
+			headers = append(headers, &h)
+		}
+	}
+	if len(headers) == 0 {
+		return list
+	}
+	return append(headers, list...)
+}
+
+func (doc *SSDoc) override(names ...string) (*SSDocType, bool) {
+	for _, name := range names {
+		v, ok := doc.types[name]
+		if !ok {
+			continue
+		}
+		typ := &SSDocType{TypeName: v, Type: CustomType}
+		if t, ok := nameTypes[v]; ok {
+			typ.Type = t
+		} else if v == "any" || v == "interface{}" {
+			typ.Type = InterfaceType
+		}
+		return typ, true
+	}
+	return nil, false
+}
+
+// 所有返回内容外层的结构, typeName 为类型的完整路径, 如 github.com/x/api.Response.
+// field 为放置返回内容的字段的 json 名字, 为空时使用 data, 外层结构的其它字段加到每个状态码的返回中
+func (doc *SSDoc) Envelope(typeName, field string) *SSDoc {
+	doc.envelope = nil
+	if typeName == "" {
+		return doc
+	}
+	if field == "" {
+		field = "data"
+	}
+
+	ts := doc.lookupType(typeName)
+	if ts == nil {
+		doc.report(&Diagnostic{Severity: SeverityError, Message: "unresolved envelope type " + typeName})
+		return doc
+	}
+
+	found := false
+	doc.envelope = []*SSDocTypeWithKey{}
+	for _, f := range fieldsOf(doc.parseType(&TypeSpecWithKey{TypeSpec: ts})) {
+		if f.name() == field {
+			found = true
+			continue
+		}
+		doc.envelope = append(doc.envelope, f)
+	}
+	if !found {
+		doc.report(&Diagnostic{Severity: SeverityWarning, Message: fmt.Sprintf("envelope type %s has no field %s", typeName, field)})
+	}
+	return doc
+}
+
+// 只解析在指定 tag 下会被编译的文件, 只对当前文档生效
+func (doc *SSDoc) BuildTags(tags ...string) *SSDoc {
+	doc.loader = doc.load().withTags(tags)
+	return doc
+}
+
+func (doc *SSDoc) Platform(goos, goarch string) *SSDoc {
+	doc.loader = doc.load().withPlatform(goos, goarch)
+	return doc
+}
 
+func (doc *SSDoc) Workers(n int) *SSDoc {
+	doc.loader = newLoader(doc.load().ctx, n)
 	return doc
 }
 
+// 没有单独设置编译条件时使用全局的 loader
+func (doc *SSDoc) load() *loader {
+	if doc.loader != nil {
+		return doc.loader
+	}
+	return defaultLoader()
+}
+
+// 按完整路径查找类型, 如 github.com/x/api.Response
+func (doc *SSDoc) lookupType(typeName string) *TypeSpec {
+	i := strings.LastIndex(typeName, ".")
+	if i <= 0 {
+		return nil
+	}
+	pkg := doc.load().get(typeName[:i])
+	if pkg == nil {
+		return nil
+	}
+	return pkg.SetPkgs().GetStru(typeName[i+1:])
+}
+
+// 每个状态码的第一个返回前加上外层结构的字段, 接口中声明了同名返回时以接口为准
+func (doc *SSDoc) wrap(rets []*SSDocRet) []*SSDocRet {
+	if len(rets) == 0 || len(doc.envelope) == 0 {
+		return rets
+	}
+
+	keys := make(map[int16]map[string]bool)
+	for _, r := range rets {
+		if keys[r.Code] == nil {
+			keys[r.Code] = make(map[string]bool)
+		}
+		keys[r.Code][r.Key] = true
+	}
+
+	list := make([]*SSDocRet, 0, len(rets)+len(doc.envelope))
+	done := make(map[int16]bool)
+	for _, r := range rets {
+		if !done[r.Code] {
+			done[r.Code] = true
+			for _, f := range doc.envelope {
+				if !keys[r.Code][f.name()] {
+					list = append(list, &SSDocRet{Code: r.Code, Key: f.name(), Value: f, Envelope: true})
+				}
+			}
+		}
+		list = append(list, r)
+	}
+	return list
+}
+
 func (doc *SSDoc) AddPacakges(pacakges ...string) *SSDoc {
-	apis := GetApis(pacakges...)
+	l := doc.load()
+	apis := l.getApis(l.expandPackages(pacakges, doc.exclude)...)
+	// 先加中间件, 接口可以使用其它包中声明的中间件
+	for _, api := range apis {
+		if api.Middleware != "" {
+			doc.AddApi(api)
+		}
+	}
 	for _, api := range apis {
-		doc.AddApi(api)
+		if api.Middleware == "" {
+			doc.AddApi(api)
+		}
 	}
 	return doc
 }
 
 func (doc *SSDoc) Export(dir string) error {
+	if err := doc.Err(); err != nil {
+		return err
+	}
 	dir = strings.TrimRight(dir, "/\\")
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
-		os.MkdirAll(dir, os.ModePerm)
-		if err != nil {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
 			return err
 		}
 	}
@@ -189,11 +575,33 @@ func (doc *SSDoc) Export(dir string) error {
 	}
 	dir += "/doc.json"
 
-	os.WriteFile(dir, js, os.ModePerm)
-	return nil
+	return os.WriteFile(dir, js, os.ModePerm)
+}
+
+// 读取导出的 doc.json
+func Load(js []byte) (*SSDoc, error) {
+	doc := &SSDoc{}
+	if err := json.Unmarshal(js, doc); err != nil {
+		return nil, err
+	}
+	if doc.Apis == nil {
+		doc.Apis = make(map[SSDocCategoryId][]*SSDocApi)
+	}
+	return doc, nil
+}
+
+// 用于读取编译进程序的 doc.json, 失败时 panic
+func MustLoad(js []byte) *SSDoc {
+	doc, err := Load(js)
+	if err != nil {
+		panic(err)
+	}
+	return doc
 }
 
-func parseType(t *TypeSpecWithKey) *SSDocTypeWithKey {
+func (doc *SSDoc) parseType(t *TypeSpecWithKey) *SSDocTypeWithKey {
+
+	doc.report(t.diags...)
 
 	typ := &SSDocTypeWithKey{
 		Key: t.Key,
@@ -205,14 +613,22 @@ func parseType(t *TypeSpecWithKey) *SSDocTypeWithKey {
 		},
 	}
 
-	if t.Doc != nil {
-		typ.Description = t.Doc[0]
+	if d := docText(t.Doc); d != "" {
+		typ.Description = d
+	}
+
+	if t.Name != "" && t.pkg != nil {
+		if o, ok := doc.override(t.pkg.Path + "." + t.Name); ok {
+			o.Name, o.Description = typ.Name, typ.Description
+			typ.SSDocType = o
+			return typ
+		}
 	}
 
 	if t.Value != nil {
 		typ.Value = make([]*SSDocTypeWithKey, 0)
 		for _, t := range t.Value {
-			a := parseType(t)
+			a := doc.parseType(t)
 			if t.Tags != nil {
 				if tag, _ := t.Tags.Get("binding"); tag != nil {
 					opt := append(tag.Options, tag.Name)
@@ -225,23 +641,168 @@ func parseType(t *TypeSpecWithKey) *SSDocTypeWithKey {
 				if tag, _ := t.Tags.Get("default"); tag != nil {
 					a.Default = &tag.Name
 				}
+				for _, l := range paramTags {
+					if tag, _ := t.Tags.Get(l.tag); tag != nil && tag.Name != "" && tag.Name != "-" {
+						a.In, a.Param = l.in, &tag.Name
+						break
+					}
+				}
 				if tag, _ := t.Tags.Get("json"); tag != nil {
-					if tag.Name == "-" {
+					if tag.Name == "-" && a.In == "" {
 						continue
 					}
-					a.Json = &tag.Name
+					if tag.Name != "-" {
+						a.Json = &tag.Name
+						if a.In == "" {
+							a.In = "body"
+						}
+					}
 				}
 			}
 			typ.Value = append(typ.Value, a)
 		}
 	} else if t.Type == TypeType {
 		v := parseTypeType(t.TypeName, t.pkg, t.file)
-		if v != nil {
-			typ.Value = []*SSDocTypeWithKey{parseType(v)}
+		if o, ok := doc.override(t.TypeName); ok {
+			o.Description = typ.Description
+			typ.SSDocType = o
+		} else if v != nil {
+			typ.Value = []*SSDocTypeWithKey{doc.parseType(v)}
 		} else {
 			typ.Type = CustomType
+			// 标准库的类型不解析, 作为自定义类型保留
+			if !isStdType(t.TypeSpec) {
+				doc.report(newDiagnostic(SeverityWarning, t.pos, t.file, "", "unresolved type %s", t.TypeName))
+			}
 		}
 
 	}
 	return typ
 }
+
+// 导入路径的第一段不含 . 的为标准库
+func isStdType(t *TypeSpec) bool {
+	i := strings.LastIndex(t.TypeName, ".")
+	if i <= 0 || t.pkg == nil {
+		return false
+	}
+	p := t.pkg.importPath(t.file, t.TypeName[:i])
+	return p != "" && !strings.Contains(strings.Split(p, "/")[0], ".")
+}
+
+// 类型注释的全部内容, 去掉 //go:generate 等指令
+func docText(lines []string) string {
+	list := []string{}
+	for _, l := range lines {
+		if directiveRegexp.MatchString(l) {
+			continue
+		}
+		list = append(list, l)
+	}
+	return strings.TrimSpace(strings.Join(list, "\n"))
+}
+
+// gin 与 echo 绑定参数使用的标签, 按优先级排列
+var paramTags = []struct {
+	tag string
+	in  string
+}{
+	{"uri", "path"},
+	{"param", "path"},
+	{"header", "header"},
+	{"query", "query"},
+	{"form", "form"},
+}
+
+// Rest 与 Body 的字段按标签拆分到各个位置, Rest 中没有标签的字段为查询参数或同名的路径参数
+func splitRequest(api *SSDocApi) *SSDocRequest {
+	req := &SSDocRequest{}
+	path := make(map[string]bool)
+	for _, p := range PathParams(api.Path) {
+		path[strings.ToLower(p)] = true
+	}
+
+	// Rest 与 Body 常用同一个结构体, 同名字段只保留先出现的一个
+	seen := make(map[string]bool)
+	add := func(list *[]*SSDocTypeWithKey, f *SSDocTypeWithKey) {
+		if k := f.Key + "\x00" + f.paramName(); !seen[k] {
+			seen[k] = true
+			*list = append(*list, f)
+		}
+	}
+
+	inBody := make(map[string]bool)
+	for _, f := range fieldsOf(api.Body) {
+		inBody[f.Key] = true
+	}
+
+	for _, body := range []bool{false, true} {
+		t := api.Rest
+		if body {
+			t = api.Body
+		}
+		for _, f := range fieldsOf(t) {
+			// Body 中也有的字段只有 json 标签时属于 Body
+			if !body && f.In == "body" && inBody[f.Key] {
+				continue
+			}
+			switch fieldIn(api, f, body) {
+			case "path":
+				add(&req.Path, f)
+			case "header":
+				add(&req.Header, f)
+			case "form":
+				add(&req.Form, f)
+			case "body":
+				add(&req.Body, f)
+			default:
+				if !body && f.Param == nil && path[strings.ToLower(f.paramName())] {
+					add(&req.Path, f)
+				} else {
+					add(&req.Query, f)
+				}
+			}
+		}
+	}
+	return req
+}
+
+func fieldIn(api *SSDocApi, f *SSDocTypeWithKey, body bool) string {
+	switch f.In {
+	case "path", "header", "query":
+		return f.In
+	case "form":
+		// gin 的 form 标签同时用于查询参数与表单
+		if !body || !hasBody(api.Method) {
+			return "query"
+		}
+		if f.Json != nil && !acceptForm(api.Accept) {
+			return "body"
+		}
+		return "form"
+	}
+	if body {
+		return "body"
+	}
+	return "query"
+}
+
+func hasBody(methods []string) bool {
+	for _, m := range methods {
+		switch strings.ToLower(m) {
+		case "get", "head", "delete", "options":
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+func acceptForm(accept []string) bool {
+	for _, a := range accept {
+		if a == "form" || a == "multipart" {
+			return true
+		}
+	}
+	return false
+}
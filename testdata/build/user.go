@@ -0,0 +1,5 @@
+package build
+
+// @Summary 用户信息
+// @Router /user/info
+func Info() {}
@@ -0,0 +1,7 @@
+//go:build internal
+
+package build
+
+// @Summary 删除用户
+// @Router /internal/user/delete
+func Delete() {}
@@ -0,0 +1,7 @@
+//go:build !windows
+
+package build
+
+type User struct {
+	Name string `json:"name"`
+}
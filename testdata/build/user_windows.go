@@ -0,0 +1,6 @@
+package build
+
+type User struct {
+	Name string `json:"name"`
+	Sid  string `json:"sid"`
+}
@@ -0,0 +1,26 @@
+package cache
+
+import "github.com/uccu/go-doc/testdata/cache/model"
+
+// @DefineFail common 401 data model.User desc="未登录"
+// @DefineHeader auth Authorization true token
+
+type UserReq struct {
+	Id int64 `json:"id" binding:"required"`
+}
+
+type UserResp struct {
+	User  *model.User           `json:"user"`
+	Extra map[string]model.User `json:"extra"`
+}
+
+// @Summary 用户信息
+// @Router /user/info
+// @Param ids query []int64 false 用户 id
+// @Param role query model.Role false
+// @Body UserReq
+// @Success 200 data UserResp
+// @Fail 404 data model.User
+// @FailRef common
+// @HeaderRef auth
+func Info() {}
@@ -0,0 +1,11 @@
+package model
+
+// 用户
+type User struct {
+	Id    int64    `json:"id"`
+	Name  string   `json:"name" binding:"required"`
+	Roles []string `json:"roles"`
+}
+
+// 角色
+type Role string
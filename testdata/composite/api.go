@@ -0,0 +1,19 @@
+package composite
+
+import "github.com/uccu/go-doc/testdata/composite/model"
+
+type Item struct {
+	Name  string     `json:"name"`
+	Owner model.User `json:"owner"`
+}
+
+// @Summary 商品列表
+// @Router /items
+// @Body []int64
+// @Success 200 list []Item
+// @Success 200 index map[string]*Item
+// @Success 200 owner *model.User
+// @Success 200 total int64
+// @Success 200 title string
+// @Fail 400 err error
+func List() {}
@@ -0,0 +1,5 @@
+package model
+
+type User struct {
+	Id int64 `json:"id"`
+}
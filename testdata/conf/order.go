@@ -0,0 +1,26 @@
+package conf
+
+import "time"
+
+type Money struct {
+	Cent int64
+}
+
+type Order struct {
+	Id        int64     `json:"id"`
+	Price     Money     `json:"price"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// @Summary 订单
+// @Router /order
+// @Server api
+// @Header Authorization true 覆盖
+// @Success 200 data Order
+func Get() {}
+
+// @Summary 订单列表
+// @Router /orders
+// @Server api
+// @Success 200 data Order
+func List() {}
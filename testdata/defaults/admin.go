@@ -0,0 +1,10 @@
+// @Prefix /admin/
+// @Method post,put
+// @Tag admin
+
+package defaults
+
+// @Summary 管理员列表
+// @Router /users
+// @Header Authorization false 覆盖
+func Admins() {}
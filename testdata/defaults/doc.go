@@ -0,0 +1,9 @@
+// Package defaults 中的接口共用分类, 服务与请求头
+//
+// @Category 用户
+// @Server api
+// @Tag user
+// @Header Authorization true 令牌
+// @Prefix /api/v1
+// @Method get
+package defaults
@@ -0,0 +1,7 @@
+// @Prefix /orders
+// @Tag order
+package defaults
+
+// @Summary 订单列表
+// @Router /list
+func Orders() {}
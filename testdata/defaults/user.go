@@ -0,0 +1,16 @@
+package defaults
+
+// @Summary 用户信息
+// @Router /user/:id
+// @Param id path int64 true
+// @Tag profile
+func Get() {}
+
+// @Summary 修改用户
+// @Router user
+// @Method put
+// @Category 其它
+func Update() {}
+
+// 普通函数
+func Plain() {}
@@ -0,0 +1,28 @@
+// Package define 中的接口共用返回与请求头
+//
+// @DefineFail common 401 msg AuthErr desc="未登录"
+// @DefineFail common 500 msg string
+// @DefineHeader auth Authorization true "token"
+// @DefineHeader auth X-Trace false 链路 id
+// @DefineFail broken 401
+package define
+
+import "github.com/uccu/go-doc/testdata/define/errs"
+
+type AuthErr struct {
+	Code   errs.Code `json:"code"`
+	Reason string    `json:"reason"`
+}
+
+// @Summary 用户信息
+// @Router /user
+// @Header X-Trace true 覆盖
+// @HeaderRef auth
+// @Fail 500 msg AuthErr
+// @FailRef common, errs.notfound
+func Get() {}
+
+// @Summary 未定义
+// @Router /missing
+// @FailRef nothing
+func Missing() {}
@@ -0,0 +1,5 @@
+package errs
+
+// @DefineFail notfound 404 msg string desc="不存在"
+
+type Code int
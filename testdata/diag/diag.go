@@ -0,0 +1,14 @@
+package diag
+
+type User struct {
+	Name string `json:"name`
+	Age  int    `json:"age"`
+}
+
+// @Summary 用户信息
+// @Router /user/info
+// @Body Usr
+// @Succes 200 data User
+// @Success 200 data User
+// @Fail 400
+func Info() {}
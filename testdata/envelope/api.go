@@ -0,0 +1,24 @@
+package envelope
+
+type Response struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data"`
+}
+
+type User struct {
+	Id int64 `json:"id"`
+}
+
+// @Summary 用户信息
+// @Router /user
+// @Success 200 data User
+// @Fail 400 code int
+// @Fail 500 message string
+func Get() {}
+
+// @Summary 健康检查
+// @Router /ping
+// @NoEnvelope
+// @Success 200 status string
+func Ping() {}
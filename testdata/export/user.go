@@ -0,0 +1,24 @@
+package export
+
+type UserRest struct {
+	Id int64 `json:"id"`
+}
+
+type UserBody struct {
+	Name string `json:"name" binding:"required"`
+	Age  int    `json:"age" default:"18"`
+}
+
+type User struct {
+	Id   int64    `json:"id"`
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+// @Summary 修改用户
+// @Router /user/:id
+// @Method put
+// @Rest UserRest
+// @Body UserBody
+// @Success 200 data User
+func Update() {}
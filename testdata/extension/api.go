@@ -0,0 +1,13 @@
+package extension
+
+// @Summary 下单
+// @Router /order
+// @Owner "交易 团队"
+// @RateLimit 100
+// @FeatureFlag new-order
+func Create() {}
+
+// @Summary 退款
+// @Router /refund
+// @RateLimit many
+func Refund() {}
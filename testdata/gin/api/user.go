@@ -0,0 +1,6 @@
+package api
+
+// @Summary 用户信息
+// @Router /user
+// @Use auth
+func User() {}
@@ -0,0 +1,8 @@
+package middleware
+
+// Auth 校验登录状态
+//
+// @Middleware auth
+// @Header Authorization true 令牌
+// @Fail 401 msg string desc="未登录"
+func Auth() {}
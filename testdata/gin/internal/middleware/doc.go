@@ -0,0 +1,5 @@
+// Package middleware 的默认值只用于包内的接口, 不属于中间件
+//
+// @Tag internal
+// @Header X-Internal true 内部
+package middleware
@@ -0,0 +1,25 @@
+package godoc
+
+// User 用户信息.
+//
+// 昵称可以重复.
+//
+//go:generate echo user
+type User struct {
+	Id int64 `json:"id"`
+}
+
+// Get 获取用户信息. 用户不存在时返回 404.
+//
+// 需要登录.
+//
+// @Router /user
+// @Success 200 data User
+//
+//go:noinline
+func Get() {}
+
+// 修改用户
+// @Router /user/update
+// @Description 只能修改自己的信息
+func Update() {}
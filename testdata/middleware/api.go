@@ -0,0 +1,25 @@
+package middleware
+
+type AuthErr struct {
+	Reason string `json:"reason"`
+}
+
+// Auth 校验登录状态
+//
+// @Middleware auth
+// @Header Authorization true 令牌
+// @Fail 401 msg AuthErr desc="未登录"
+// @Tag login
+func Auth() {}
+
+// @Summary 用户信息
+// @Router /user
+// @Use auth, tenant
+// @Header Authorization false 覆盖
+// @Tag user
+func Get() {}
+
+// @Summary 未知中间件
+// @Router /unknown
+// @Use nothing
+func Unknown() {}
@@ -0,0 +1,14 @@
+module example.com/app
+
+go 1.18
+
+require (
+	example.com/models v1.2.0 // indirect
+	github.com/shopspring/decimal v1.3.1
+)
+
+replace example.com/models => ../models
+
+replace (
+	example.com/lib v1.0.0 => example.com/fork/lib v1.0.1
+)
@@ -0,0 +1,7 @@
+module example.com/app
+
+go 1.18
+
+require example.com/models v1.0.0
+
+replace example.com/models => ../models
@@ -0,0 +1,5 @@
+module example.com/models
+
+go 1.18
+
+replace example.com/nested => ../nested
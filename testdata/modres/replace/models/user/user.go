@@ -0,0 +1,5 @@
+package user
+
+type User struct {
+	Id int64 `json:"id"`
+}
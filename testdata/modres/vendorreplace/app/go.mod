@@ -0,0 +1,10 @@
+module example.com/vendapp
+
+go 1.18
+
+require (
+	example.com/dep v1.0.0
+	example.com/lib v1.0.0
+)
+
+replace example.com/lib => ../lib
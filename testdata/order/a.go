@@ -0,0 +1,9 @@
+package order
+
+// @Summary 第二
+// @Router /b
+func B() {}
+
+// @Summary 第三
+// @Router /c
+func C() {}
@@ -0,0 +1,11 @@
+package order
+
+// @Summary 第一
+// @Router /a
+// @Order -1
+func A() {}
+
+// @Summary 第四
+// @Router /d
+// @Order 1
+func D() {}
@@ -0,0 +1,19 @@
+package param
+
+// 状态
+type Status int
+
+// @Summary 订单列表
+// @Router /user/:uid/orders
+// @Method get
+// @Param uid path int64 false 用户
+// @Param status query Status false "订单 状态"
+// @Param tags query []string false
+// @Param X-Token header string true 令牌
+// @Param note form string required 备注
+// @Param bad body string true
+// @Param page query int "页码"
+// @Param size query int
+// @Param sort query string optional 排序
+// @Param q query string search keyword here
+func List() {}
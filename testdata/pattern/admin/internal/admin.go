@@ -0,0 +1,5 @@
+package internal
+
+// @Summary 管理员
+// @Router /admin
+func Admin() {}
@@ -0,0 +1,5 @@
+package order
+
+// @Summary 订单列表
+// @Router /order/list
+func List() {}
@@ -0,0 +1,27 @@
+package request
+
+type UpdateReq struct {
+	Id    int64  `uri:"id" json:"-"`
+	Token string `header:"X-Token" json:"-"`
+	Page  int    `form:"page" json:"-"`
+	Name  string `json:"name"`
+	Age   int    `json:"age"`
+}
+
+type ListReq struct {
+	Uid  int64
+	Page int    `form:"page"`
+	Sort string `query:"sort"`
+}
+
+// @Summary 更新用户
+// @Router /user/:id
+// @Rest UpdateReq
+// @Body UpdateReq
+func Update() {}
+
+// @Summary 用户列表
+// @Router /user/:uid/list
+// @Method get
+// @Rest ListReq
+func List() {}
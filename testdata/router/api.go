@@ -0,0 +1,30 @@
+package router
+
+type GetReq struct {
+	// 用户 id
+	Id int64 `uri:"id"`
+}
+
+type OrderReq struct {
+	Uid int64 `uri:"uid"`
+	Oid int64 `uri:"order_id"`
+}
+
+// @Summary 用户信息
+// @Router /user/{id}
+// @Method get
+// @Rest GetReq
+func Get() {}
+
+// @Summary 下载文件
+// @Router /files/*path
+// @Method get
+// @Param path path string true 文件路径
+func Download() {}
+
+// @Summary 订单信息
+// @Router /user/:uid/order/:oid
+// @Method get
+// @Rest OrderReq
+// @Param name path string true
+func Order() {}
@@ -0,0 +1,26 @@
+package strict
+
+// @Summary 用户信息
+// @Router /user/info
+func Info() {}
+
+// @Summary 用户信息
+// @Router /user/info
+func Info2() {}
+
+// @Summary 没有路径
+func NoRouter() {}
+
+// @Summary 管理后台
+// @Router /admin/info
+// @Server admin
+func Admin() {}
+
+// @Summary 列表
+// @Router /list
+// @Success 200 list []Missing
+// @Success 200 map map[string]Missing
+func List() {}
+
+// 普通函数
+func Plain() {}
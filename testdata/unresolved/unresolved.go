@@ -0,0 +1,22 @@
+package unresolved
+
+import (
+	"time"
+
+	"example.com/missing"
+)
+
+type Record struct {
+	CreatedAt time.Time     `json:"createdAt"`
+	Owner     missing.Owner `json:"owner"`
+}
+
+// @Summary 记录
+// @Router /record
+// @Success 200 data Record
+func Get() {}
+
+// @Summary 记录列表
+// @Router /records
+// @Success 200 list []Record
+func List() {}